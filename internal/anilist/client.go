@@ -4,9 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/rayanxn/ani-tui/internal/cache"
 )
 
 const graphqlEndpoint = "https://graphql.anilist.co"
@@ -15,8 +21,38 @@ const graphqlEndpoint = "https://graphql.anilist.co"
 type Client struct {
 	token      string
 	httpClient *http.Client
+	cache      *cache.Cache
+	outbox     *Outbox
+	viewer     int         // AniList user ID of the token's owner, 0 if unknown
+	offline    atomic.Bool // set while AniList is unreachable
+	forced     bool        // offline for the whole session
 }
 
+// Cache namespaces, one per endpoint, and how long their entries count as
+// fresh. Stale entries are still served by the Cached* methods so views can
+// show them while refreshing.
+const (
+	nsDetails   = "anilist-details"
+	nsRelations = "anilist-relations"
+	nsList      = "anilist-list"
+	nsSearch    = "anilist-search"
+	nsCredits   = "anilist-credits"
+	nsActivity  = "anilist-activity"
+	nsStats     = "anilist-stats"
+
+	detailsTTL   = 6 * time.Hour
+	relationsTTL = 7 * 24 * time.Hour
+	listTTL      = 10 * time.Minute
+	searchTTL    = time.Hour
+	activityTTL  = 5 * time.Minute
+	statsTTL     = time.Hour
+)
+
+// viewerScoped lists the namespaces whose responses depend on who asks:
+// details carry the viewer's list entry and favourite flag, and activity
+// comes from the users they follow. Their cache keys include the viewer.
+var viewerScoped = map[string]bool{nsDetails: true, nsActivity: true}
+
 // NewClient creates a new AniList client. Token may be empty for public queries.
 func NewClient(token string) *Client {
 	return &Client{
@@ -25,6 +61,18 @@ func NewClient(token string) *Client {
 	}
 }
 
+// SetCache makes the client store responses in c and serve them from the
+// Cached* methods.
+func (c *Client) SetCache(cc *cache.Cache) {
+	c.cache = cc
+}
+
+// SetViewer records the AniList user ID of the token's owner, so cached
+// responses of one account are never served to another.
+func (c *Client) SetViewer(userID int) {
+	c.viewer = userID
+}
+
 // graphqlRequest is the JSON body sent to the AniList GraphQL endpoint.
 type graphqlRequest struct {
 	Query     string         `json:"query"`
@@ -41,14 +89,65 @@ type graphqlResponse struct {
 
 // doQuery executes a GraphQL query and unmarshals the data field into target.
 func (c *Client) doQuery(ctx context.Context, query string, vars map[string]any, target any) error {
+	_, err := c.doQueryRaw(ctx, query, vars, target)
+	return err
+}
+
+// doCachedQuery is doQuery that also stores the response in namespace ns.
+// While offline it answers from the cache regardless of age.
+func (c *Client) doCachedQuery(ctx context.Context, ns, query string, vars map[string]any, target any) error {
+	if !c.Offline() {
+		data, err := c.doQueryRaw(ctx, query, vars, target)
+		if err == nil {
+			_ = c.cache.Put(ns, c.cacheKey(ns, vars), data) // best-effort
+			return nil
+		}
+		if !errors.Is(err, ErrOffline) {
+			return err
+		}
+	}
+	if _, ok := c.cached(ns, 0, vars, target); ok {
+		return nil
+	}
+	return fmt.Errorf("%w: not cached yet", ErrOffline)
+}
+
+// cached decodes the response stored in ns for vars into target. fresh
+// reports whether it is younger than ttl.
+func (c *Client) cached(ns string, ttl time.Duration, vars map[string]any, target any) (fresh, ok bool) {
+	data, age, ok := c.cache.Get(ns, c.cacheKey(ns, vars))
+	if !ok || json.Unmarshal(data, target) != nil {
+		return false, false
+	}
+	return age < ttl, true
+}
+
+// cacheKey identifies a query's response within namespace ns.
+func (c *Client) cacheKey(ns string, vars map[string]any) string {
+	if viewerScoped[ns] {
+		vars = maps.Clone(vars)
+		if vars == nil {
+			vars = make(map[string]any)
+		}
+		vars["viewer"] = c.viewer
+	}
+	key, _ := json.Marshal(vars) // map keys are sorted, so this is stable
+	return string(key)
+}
+
+// doQueryRaw is doQuery that also returns the raw data field.
+func (c *Client) doQueryRaw(ctx context.Context, query string, vars map[string]any, target any) (json.RawMessage, error) {
+	if c.forced {
+		return nil, ErrOffline
+	}
 	body, err := json.Marshal(graphqlRequest{Query: query, Variables: vars})
 	if err != nil {
-		return fmt.Errorf("marshal request: %w", err)
+		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphqlEndpoint, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
@@ -58,35 +157,42 @@ func (c *Client) doQuery(ctx context.Context, query string, vars map[string]any,
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		// No response at all: treat AniList as unreachable until a later
+		// request gets through.
+		c.offline.Store(true)
+		return nil, fmt.Errorf("request failed: %w: %w", ErrOffline, err)
 	}
 	defer resp.Body.Close()
+	c.offline.Store(false)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("read response: %w", err)
+		return nil, fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("AniList API error (status %d): %s", resp.StatusCode, respBody)
+		return nil, fmt.Errorf("AniList API error (status %d): %s", resp.StatusCode, respBody)
 	}
 
 	var gqlResp graphqlResponse
 	if err := json.Unmarshal(respBody, &gqlResp); err != nil {
-		return fmt.Errorf("unmarshal response: %w", err)
+		return nil, fmt.Errorf("unmarshal response: %w", err)
 	}
 
 	if len(gqlResp.Errors) > 0 {
-		return fmt.Errorf("AniList: %s", gqlResp.Errors[0].Message)
+		return nil, fmt.Errorf("AniList: %s", gqlResp.Errors[0].Message)
 	}
 
 	if target != nil {
 		if err := json.Unmarshal(gqlResp.Data, target); err != nil {
-			return fmt.Errorf("unmarshal data: %w", err)
+			return nil, fmt.Errorf("unmarshal data: %w", err)
 		}
 	}
 
-	return nil
+	return gqlResp.Data, nil
 }
 
 // SearchAnime searches for anime by name.
@@ -103,13 +209,25 @@ func (c *Client) SearchAnime(ctx context.Context, search string, page int) ([]Me
 		"page":   page,
 	}
 
-	if err := c.doQuery(ctx, searchAnimeQuery, vars, &result); err != nil {
+	if err := c.doCachedQuery(ctx, nsSearch, searchAnimeQuery, vars, &result); err != nil {
 		return nil, err
 	}
 
 	return result.Page.Media, nil
 }
 
+// CachedSearchAnime returns the last SearchAnime results for search and
+// page, if any, and whether they are still fresh.
+func (c *Client) CachedSearchAnime(search string, page int) (media []Media, fresh, ok bool) {
+	var result struct {
+		Page struct {
+			Media []Media `json:"media"`
+		} `json:"Page"`
+	}
+	fresh, ok = c.cached(nsSearch, searchTTL, map[string]any{"search": search, "page": page}, &result)
+	return result.Page.Media, fresh, ok
+}
+
 // GetAnimeDetails retrieves full details for a specific anime.
 func (c *Client) GetAnimeDetails(ctx context.Context, id int) (Media, error) {
 	var result struct {
@@ -118,13 +236,93 @@ func (c *Client) GetAnimeDetails(ctx context.Context, id int) (Media, error) {
 
 	vars := map[string]any{"id": id}
 
-	if err := c.doQuery(ctx, getAnimeDetailsQuery, vars, &result); err != nil {
+	if err := c.doCachedQuery(ctx, nsDetails, getAnimeDetailsQuery, vars, &result); err != nil {
 		return Media{}, err
 	}
 
 	return result.Media, nil
 }
 
+// CachedAnimeDetails returns the last GetAnimeDetails result for id, if any,
+// and whether it is still fresh.
+func (c *Client) CachedAnimeDetails(id int) (media Media, fresh, ok bool) {
+	var result struct {
+		Media Media `json:"Media"`
+	}
+	fresh, ok = c.cached(nsDetails, detailsTTL, map[string]any{"id": id}, &result)
+	return result.Media, fresh, ok
+}
+
+// maxPrequels bounds how far EpisodeOffset walks a prequel chain.
+const maxPrequels = 20
+
+// EpisodeOffset walks the prequel chain of m and returns how many episodes
+// air before it, so that its episode n is episode offset+n in absolute
+// numbering. first is the earliest season in the chain (m itself when it has
+// no prequel). It fails if an earlier season's episode count is unknown.
+func (c *Client) EpisodeOffset(ctx context.Context, m Media) (offset int, first Media, err error) {
+	first = m
+	seen := map[int]bool{m.ID: true}
+	for range maxPrequels {
+		prequel, ok := first.Prequel()
+		if !ok || seen[prequel.ID] {
+			break
+		}
+		if prequel.Episodes == 0 {
+			return 0, m, fmt.Errorf("episode count unknown for anime %d", prequel.ID)
+		}
+		seen[prequel.ID] = true
+		offset += prequel.Episodes
+
+		var result struct {
+			Media Media `json:"Media"`
+		}
+		vars := map[string]any{"id": prequel.ID}
+		if fresh, _ := c.cached(nsRelations, relationsTTL, vars, &result); !fresh {
+			if err := c.doCachedQuery(ctx, nsRelations, getRelationsQuery, vars, &result); err != nil {
+				return 0, m, err
+			}
+		}
+		first = result.Media
+	}
+	return offset, first, nil
+}
+
+// GetCharacters retrieves one page of an anime's characters, with their
+// voice actors in language (a StaffLanguage such as "JAPANESE").
+func (c *Client) GetCharacters(ctx context.Context, id, page int, language string) (CharacterConnection, error) {
+	var result struct {
+		Media struct {
+			Characters CharacterConnection `json:"characters"`
+		} `json:"Media"`
+	}
+
+	vars := map[string]any{"id": id, "page": page, "language": language}
+
+	if err := c.doCachedQuery(ctx, nsCredits, getCharactersQuery, vars, &result); err != nil {
+		return CharacterConnection{}, err
+	}
+
+	return result.Media.Characters, nil
+}
+
+// GetStaff retrieves one page of an anime's staff.
+func (c *Client) GetStaff(ctx context.Context, id, page int) (StaffConnection, error) {
+	var result struct {
+		Media struct {
+			Staff StaffConnection `json:"staff"`
+		} `json:"Media"`
+	}
+
+	vars := map[string]any{"id": id, "page": page}
+
+	if err := c.doCachedQuery(ctx, nsCredits, getStaffQuery, vars, &result); err != nil {
+		return StaffConnection{}, err
+	}
+
+	return result.Media.Staff, nil
+}
+
 // GetUserList retrieves a user's anime list.
 func (c *Client) GetUserList(ctx context.Context, userID int) (MediaListCollection, error) {
 	var result struct {
@@ -133,22 +331,202 @@ func (c *Client) GetUserList(ctx context.Context, userID int) (MediaListCollecti
 
 	vars := map[string]any{"userId": userID}
 
-	if err := c.doQuery(ctx, getUserListQuery, vars, &result); err != nil {
+	if err := c.doCachedQuery(ctx, nsList, getUserListQuery, vars, &result); err != nil {
 		return MediaListCollection{}, err
 	}
 
 	return result.MediaListCollection, nil
 }
 
-// UpdateProgress updates the watch progress for an anime.
+// CachedUserList returns the last GetUserList result for userID, if any,
+// and whether it is still fresh.
+func (c *Client) CachedUserList(userID int) (collection MediaListCollection, fresh, ok bool) {
+	var result struct {
+		MediaListCollection MediaListCollection `json:"MediaListCollection"`
+	}
+	fresh, ok = c.cached(nsList, listTTL, map[string]any{"userId": userID}, &result)
+	return result.MediaListCollection, fresh, ok
+}
+
+// userStats is the shape of a GetUserStats response.
+type userStats struct {
+	User struct {
+		Statistics struct {
+			Anime UserStatistics `json:"anime"`
+		} `json:"statistics"`
+	} `json:"User"`
+}
+
+// GetUserStats retrieves a user's anime statistics.
+func (c *Client) GetUserStats(ctx context.Context, userID int) (UserStatistics, error) {
+	var result userStats
+
+	vars := map[string]any{"userId": userID}
+
+	if err := c.doCachedQuery(ctx, nsStats, getUserStatsQuery, vars, &result); err != nil {
+		return UserStatistics{}, err
+	}
+
+	return result.User.Statistics.Anime, nil
+}
+
+// CachedUserStats returns the last GetUserStats result for userID, if any,
+// and whether it is still fresh.
+func (c *Client) CachedUserStats(userID int) (stats UserStatistics, fresh, ok bool) {
+	var result userStats
+	fresh, ok = c.cached(nsStats, statsTTL, map[string]any{"userId": userID}, &result)
+	return result.User.Statistics.Anime, fresh, ok
+}
+
+// UpdateProgress updates the watch progress for an anime. While offline the
+// update waits in the outbox, if there is one, and nil is returned.
 func (c *Client) UpdateProgress(ctx context.Context, mediaID, progress int, status string) error {
+	return c.sendOrQueue(ctx, Mutation{Kind: MutationProgress, MediaID: mediaID, Progress: progress, Status: status})
+}
+
+// saveProgress sends a progress update to AniList.
+func (c *Client) saveProgress(ctx context.Context, mediaID, progress int, status string) error {
 	vars := map[string]any{
 		"mediaId":  mediaID,
 		"progress": progress,
 		"status":   status,
 	}
 
-	return c.doQuery(ctx, updateProgressMutation, vars, nil)
+	if err := c.doQuery(ctx, updateProgressMutation, vars, nil); err != nil {
+		return err
+	}
+	c.invalidate(mediaID, true)
+	return nil
+}
+
+// invalidate drops the cached responses a change to mediaID made stale: its
+// details and, when listChanged, the viewer's list and stats. Without a known
+// viewer every cached list and stats response goes.
+func (c *Client) invalidate(mediaID int, listChanged bool) {
+	_ = c.cache.Delete(nsDetails, c.cacheKey(nsDetails, map[string]any{"id": mediaID}))
+	if !listChanged {
+		return
+	}
+	for _, ns := range []string{nsList, nsStats} {
+		if c.viewer == 0 {
+			_ = c.cache.Invalidate(ns)
+			continue
+		}
+		_ = c.cache.Delete(ns, c.cacheKey(ns, map[string]any{"userId": c.viewer}))
+	}
+}
+
+// ToggleFavourite adds an anime to the viewer's favourites, or removes it if
+// it is already there. While offline the toggle waits in the outbox, if
+// there is one, and nil is returned.
+func (c *Client) ToggleFavourite(ctx context.Context, animeID int) error {
+	return c.sendOrQueue(ctx, Mutation{Kind: MutationFavourite, MediaID: animeID})
+}
+
+// toggleFavourite sends a favourite toggle to AniList.
+func (c *Client) toggleFavourite(ctx context.Context, animeID int) error {
+	vars := map[string]any{"animeId": animeID}
+
+	if err := c.doQuery(ctx, toggleFavouriteMutation, vars, nil); err != nil {
+		return err
+	}
+	// Cached details still carry the old isFavourite.
+	c.invalidate(animeID, false)
+	return nil
+}
+
+// activityPage is the shape of a GetActivity response.
+type activityPage struct {
+	Page struct {
+		PageInfo   PageInfo       `json:"pageInfo"`
+		Activities []ListActivity `json:"activities"`
+	} `json:"Page"`
+}
+
+// activities drops entries that aren't list activity, which decode empty.
+func (p activityPage) activities() []ListActivity {
+	var out []ListActivity
+	for _, a := range p.Page.Activities {
+		if a.ID != 0 && a.Media.ID != 0 {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// GetActivity retrieves a page of recent anime list activity from the users
+// the viewer follows. more reports whether there is another page.
+func (c *Client) GetActivity(ctx context.Context, page int) (activities []ListActivity, more bool, err error) {
+	var result activityPage
+
+	vars := map[string]any{"page": page}
+
+	if err := c.doCachedQuery(ctx, nsActivity, getActivityQuery, vars, &result); err != nil {
+		return nil, false, err
+	}
+
+	return result.activities(), result.Page.PageInfo.HasNextPage, nil
+}
+
+// CachedActivity returns the last GetActivity result for page, if any, and
+// whether it is still fresh.
+func (c *Client) CachedActivity(page int) (activities []ListActivity, more, fresh, ok bool) {
+	var result activityPage
+	fresh, ok = c.cached(nsActivity, activityTTL, map[string]any{"page": page}, &result)
+	return result.activities(), result.Page.PageInfo.HasNextPage, fresh, ok
+}
+
+// Authenticated reports whether the client has a token for viewer-only
+// queries and mutations.
+func (c *Client) Authenticated() bool {
+	return c.token != ""
+}
+
+// GetCustomLists retrieves the names of the viewer's custom anime lists.
+func (c *Client) GetCustomLists(ctx context.Context) ([]string, error) {
+	var result struct {
+		Viewer struct {
+			MediaListOptions struct {
+				AnimeList struct {
+					CustomLists []string `json:"customLists"`
+				} `json:"animeList"`
+			} `json:"mediaListOptions"`
+		} `json:"Viewer"`
+	}
+
+	if err := c.doQuery(ctx, customListsQuery, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Viewer.MediaListOptions.AnimeList.CustomLists, nil
+}
+
+// SaveCustomLists puts an anime on exactly the given custom lists. An anime
+// not on the viewer's list yet is added with status, which is otherwise
+// left alone and may be empty. While offline the change waits in the
+// outbox, if there is one, and nil is returned.
+func (c *Client) SaveCustomLists(ctx context.Context, mediaID int, lists []string, status string) error {
+	return c.sendOrQueue(ctx, Mutation{Kind: MutationCustomLists, MediaID: mediaID, CustomLists: lists, Status: status})
+}
+
+// saveCustomLists sends a custom lists change to AniList.
+func (c *Client) saveCustomLists(ctx context.Context, mediaID int, lists []string, status string) error {
+	if lists == nil {
+		lists = []string{} // null would leave the lists unchanged
+	}
+	vars := map[string]any{
+		"mediaId":     mediaID,
+		"customLists": lists,
+	}
+	if status != "" {
+		vars["status"] = status
+	}
+
+	if err := c.doQuery(ctx, saveCustomListsMutation, vars, nil); err != nil {
+		return err
+	}
+	c.invalidate(mediaID, true)
+	return nil
 }
 
 // GetViewer retrieves the authenticated user's information.
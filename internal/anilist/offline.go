@@ -0,0 +1,231 @@
+package anilist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rayanxn/ani-tui/internal/fsutil"
+)
+
+// ErrOffline is returned when a request needs AniList but the client is
+// offline and has nothing cached to answer with.
+var ErrOffline = errors.New("offline")
+
+// pingQuery is the cheapest query that proves AniList is reachable.
+const pingQuery = `query { Page(perPage: 1) { pageInfo { total } } }`
+
+// ForceOffline keeps the client offline for the rest of the session: no
+// requests are made and only cached responses are served.
+func (c *Client) ForceOffline() {
+	c.forced = true
+	c.offline.Store(true)
+}
+
+// Offline reports whether the client is serving from the cache, either
+// because it was forced to or because the last request couldn't reach
+// AniList.
+func (c *Client) Offline() bool {
+	return c.offline.Load()
+}
+
+// Forced reports whether offline mode was forced with ForceOffline.
+func (c *Client) Forced() bool {
+	return c.forced
+}
+
+// Ping checks whether AniList is reachable, updating Offline accordingly.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.doQueryRaw(ctx, pingQuery, nil, nil)
+	return err
+}
+
+// SetOutbox makes list mutations made while offline wait in o instead of
+// failing.
+func (c *Client) SetOutbox(o *Outbox) {
+	c.outbox = o
+}
+
+// Outbox returns the client's outbox, which may be nil.
+func (c *Client) Outbox() *Outbox {
+	return c.outbox
+}
+
+// FlushOutbox replays queued mutations in order, stopping at the first
+// failure. It returns how many were sent.
+func (c *Client) FlushOutbox(ctx context.Context) (int, error) {
+	sent := 0
+	for _, m := range c.outbox.Pending() {
+		if err := c.send(ctx, m); err != nil {
+			return sent, err
+		}
+		// Only the entry just sent: a newer one queued meanwhile stays.
+		if err := c.outbox.Remove(m.Seq); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// sendOrQueue sends m, or queues it in the outbox when AniList can't be
+// reached and there is one.
+func (c *Client) sendOrQueue(ctx context.Context, m Mutation) error {
+	if c.Offline() && c.outbox != nil {
+		return c.outbox.Add(m)
+	}
+	err := c.send(ctx, m)
+	if errors.Is(err, ErrOffline) && c.outbox != nil {
+		return c.outbox.Add(m)
+	}
+	return err
+}
+
+// send sends m to AniList.
+func (c *Client) send(ctx context.Context, m Mutation) error {
+	switch m.Kind {
+	case MutationProgress:
+		return c.saveProgress(ctx, m.MediaID, m.Progress, m.Status)
+	case MutationFavourite:
+		return c.toggleFavourite(ctx, m.MediaID)
+	case MutationCustomLists:
+		return c.saveCustomLists(ctx, m.MediaID, m.CustomLists, m.Status)
+	}
+	return fmt.Errorf("unknown mutation kind %q", m.Kind)
+}
+
+// MutationKind says what a Mutation changes.
+type MutationKind string
+
+// Mutation kinds. Progress updates have the empty kind, as they did when
+// they were the only kind.
+const (
+	MutationProgress    MutationKind = ""
+	MutationFavourite   MutationKind = "favourite"
+	MutationCustomLists MutationKind = "custom_lists"
+)
+
+// Mutation is a list change made while offline.
+type Mutation struct {
+	Seq         int64        `json:"seq"` // unique within the outbox, increasing
+	Kind        MutationKind `json:"kind,omitempty"`
+	MediaID     int          `json:"media_id"`
+	Progress    int          `json:"progress,omitempty"`
+	Status      string       `json:"status,omitempty"`
+	CustomLists []string     `json:"custom_lists,omitempty"`
+	Queued      time.Time    `json:"queued"`
+}
+
+// Outbox persists mutations made while offline until they can be sent.
+// Only the latest progress update and custom lists change per anime are
+// kept; every favourite toggle is, since each one flips the state. A nil
+// *Outbox holds nothing.
+type Outbox struct {
+	path string
+
+	mu    sync.Mutex
+	items []Mutation
+	next  int64 // Seq of the next mutation
+}
+
+// OpenOutbox loads the outbox at path, starting empty if the file doesn't
+// exist yet.
+func OpenOutbox(path string) (*Outbox, error) {
+	o := &Outbox{path: path, next: 1}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return o, nil
+		}
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	if err := json.Unmarshal(data, &o.items); err != nil {
+		return nil, fmt.Errorf("parse outbox: %w", err)
+	}
+	for _, it := range o.items {
+		o.next = max(o.next, it.Seq+1)
+	}
+	// Outboxes written before mutations were numbered.
+	for i := range o.items {
+		if o.items[i].Seq == 0 {
+			o.items[i].Seq = o.next
+			o.next++
+		}
+	}
+	return o, nil
+}
+
+// Add queues m, replacing any earlier mutation of the same kind and anime
+// unless it is a favourite toggle.
+func (o *Outbox) Add(m Mutation) error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if m.Queued.IsZero() {
+		m.Queued = time.Now()
+	}
+	for i, it := range o.items {
+		if it.MediaID != m.MediaID || it.Kind != m.Kind || m.Kind == MutationFavourite {
+			continue
+		}
+		o.items = append(o.items[:i], o.items[i+1:]...)
+		if m.Status == "" {
+			m.Status = it.Status // still needed if the anime wasn't on the list
+		}
+		break
+	}
+	m.Seq = o.next
+	o.next++
+	o.items = append(o.items, m)
+	return o.saveLocked()
+}
+
+// Remove drops the queued mutation numbered seq, if it is still queued.
+func (o *Outbox) Remove(seq int64) error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i, it := range o.items {
+		if it.Seq == seq {
+			o.items = append(o.items[:i], o.items[i+1:]...)
+			return o.saveLocked()
+		}
+	}
+	return nil
+}
+
+// Pending returns the queued mutations, oldest first.
+func (o *Outbox) Pending() []Mutation {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Mutation(nil), o.items...)
+}
+
+// Len returns how many mutations are queued.
+func (o *Outbox) Len() int {
+	if o == nil {
+		return 0
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.items)
+}
+
+// saveLocked writes the outbox atomically. Callers must hold o.mu.
+func (o *Outbox) saveLocked() error {
+	if err := fsutil.WriteJSONAtomic(o.path, o.items); err != nil {
+		return fmt.Errorf("save outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package anilist
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutbox_RemoveKeepsNewerMutation(t *testing.T) {
+	t.Parallel()
+
+	o, err := OpenOutbox(filepath.Join(t.TempDir(), "outbox.json"))
+	if err != nil {
+		t.Fatalf("OpenOutbox: %v", err)
+	}
+	_ = o.Add(Mutation{MediaID: 1, Progress: 3, Status: "CURRENT"})
+	sending := o.Pending()[0]
+
+	// Episode 4 is watched while episode 3 is being sent.
+	_ = o.Add(Mutation{MediaID: 1, Progress: 4})
+	if err := o.Remove(sending.Seq); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	pending := o.Pending()
+	if len(pending) != 1 || pending[0].Progress != 4 || pending[0].Status != "CURRENT" {
+		t.Fatalf("Pending = %+v, want the newer update with the earlier status", pending)
+	}
+}
+
+func TestOutbox_KindsAndToggles(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	o, err := OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenOutbox: %v", err)
+	}
+	_ = o.Add(Mutation{MediaID: 1, Progress: 2})
+	_ = o.Add(Mutation{Kind: MutationFavourite, MediaID: 1})
+	_ = o.Add(Mutation{Kind: MutationCustomLists, MediaID: 1, CustomLists: []string{"Rewatch"}})
+	_ = o.Add(Mutation{Kind: MutationFavourite, MediaID: 1})
+	_ = o.Add(Mutation{Kind: MutationCustomLists, MediaID: 1})
+
+	reopened, err := OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	var kinds []MutationKind
+	for _, m := range reopened.Pending() {
+		kinds = append(kinds, m.Kind)
+	}
+	want := []MutationKind{MutationProgress, MutationFavourite, MutationFavourite, MutationCustomLists}
+	if len(kinds) != len(want) {
+		t.Fatalf("kinds = %q, want %q", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("kinds = %q, want %q", kinds, want)
+		}
+	}
+	if last := reopened.Pending()[3]; len(last.CustomLists) != 0 {
+		t.Fatalf("custom lists = %q, want the latest (none)", last.CustomLists)
+	}
+}
+
+func TestOutbox_NumbersOldEntries(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	data := `[{"media_id": 1, "progress": 2, "status": "CURRENT"}, {"media_id": 2, "progress": 5, "status": "CURRENT"}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	o, err := OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenOutbox: %v", err)
+	}
+	_ = o.Add(Mutation{MediaID: 3, Progress: 1})
+	seen := map[int64]bool{}
+	for _, m := range o.Pending() {
+		if m.Seq == 0 || seen[m.Seq] {
+			t.Fatalf("Pending = %+v, want distinct sequence numbers", o.Pending())
+		}
+		seen[m.Seq] = true
+	}
+
+	var nilOutbox *Outbox
+	if err := nilOutbox.Add(Mutation{MediaID: 1}); err != nil {
+		t.Fatalf("nil Add: %v", err)
+	}
+	if err := nilOutbox.Remove(1); err != nil {
+		t.Fatalf("nil Remove: %v", err)
+	}
+}
@@ -32,6 +32,7 @@ const getAnimeDetailsQuery = `
 query GetAnimeDetails($id: Int!) {
   Media(id: $id, type: ANIME) {
     id
+    type
     title {
       romaji
       english
@@ -57,6 +58,170 @@ query GetAnimeDetails($id: Int!) {
       airingAt
       timeUntilAiring
     }
+    synonyms
+    relations {
+      edges {
+        relationType
+        node {
+          id
+          type
+          format
+          status
+          episodes
+          seasonYear
+          title {
+            romaji
+            english
+          }
+          synonyms
+        }
+      }
+    }
+    recommendations(perPage: 10, sort: RATING_DESC) {
+      nodes {
+        rating
+        mediaRecommendation {
+          id
+          type
+          format
+          seasonYear
+          averageScore
+          title {
+            romaji
+            english
+          }
+        }
+      }
+    }
+    airingSchedule(notYetAired: true, perPage: 50) {
+      nodes {
+        episode
+        airingAt
+        timeUntilAiring
+      }
+    }
+    mediaListEntry {
+      id
+      status
+      progress
+      customLists
+    }
+    isFavourite
+    streamingEpisodes {
+      title
+      thumbnail
+      url
+      site
+    }
+    coverImage {
+      large
+      color
+    }
+    stats {
+      scoreDistribution {
+        score
+        amount
+      }
+      statusDistribution {
+        status
+        amount
+      }
+    }
+  }
+}
+`
+
+// getRelationsQuery retrieves an anime's episode count and relations, used to
+// walk prequel chains
+const getRelationsQuery = `
+query GetRelations($id: Int!) {
+  Media(id: $id, type: ANIME) {
+    id
+    type
+    format
+    episodes
+    title {
+      romaji
+      english
+    }
+    synonyms
+    relations {
+      edges {
+        relationType
+        node {
+          id
+          type
+          format
+          episodes
+          title {
+            romaji
+            english
+          }
+          synonyms
+        }
+      }
+    }
+  }
+}
+`
+
+// getCharactersQuery retrieves a page of an anime's characters with their
+// voice actors in one language
+const getCharactersQuery = `
+query GetCharacters($id: Int!, $page: Int, $language: StaffLanguage) {
+  Media(id: $id, type: ANIME) {
+    characters(page: $page, perPage: 25, sort: [ROLE, RELEVANCE, ID]) {
+      pageInfo {
+        total
+        currentPage
+        lastPage
+        hasNextPage
+      }
+      edges {
+        role
+        node {
+          id
+          name {
+            full
+            native
+          }
+        }
+        voiceActors(language: $language, sort: [RELEVANCE, ID]) {
+          id
+          name {
+            full
+            native
+          }
+          languageV2
+        }
+      }
+    }
+  }
+}
+`
+
+// getStaffQuery retrieves a page of an anime's staff
+const getStaffQuery = `
+query GetStaff($id: Int!, $page: Int) {
+  Media(id: $id, type: ANIME) {
+    staff(page: $page, perPage: 25, sort: [RELEVANCE, ID]) {
+      pageInfo {
+        total
+        currentPage
+        lastPage
+        hasNextPage
+      }
+      edges {
+        role
+        node {
+          id
+          name {
+            full
+            native
+          }
+        }
+      }
+    }
   }
 }
 `
@@ -66,12 +231,15 @@ const getUserListQuery = `
 query GetUserList($userId: Int!) {
   MediaListCollection(userId: $userId, type: ANIME) {
     lists {
+      name
+      isCustomList
       status
       entries {
         id
         status
         progress
         score
+        updatedAt
         media {
           id
           title {
@@ -83,6 +251,59 @@ query GetUserList($userId: Int!) {
           status
           episodes
           averageScore
+          nextAiringEpisode {
+            episode
+            airingAt
+            timeUntilAiring
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+// getUserStatsQuery retrieves a user's anime statistics and breakdowns
+const getUserStatsQuery = `
+query GetUserStats($userId: Int!) {
+  User(id: $userId) {
+    statistics {
+      anime {
+        count
+        episodesWatched
+        minutesWatched
+        meanScore
+        genres(limit: 10, sort: COUNT_DESC) {
+          genre
+          count
+          meanScore
+          minutesWatched
+        }
+        formats(sort: COUNT_DESC) {
+          format
+          count
+          meanScore
+          minutesWatched
+        }
+        studios(limit: 10, sort: COUNT_DESC) {
+          studio {
+            name
+          }
+          count
+          meanScore
+          minutesWatched
+        }
+        releaseYears(sort: ID) {
+          releaseYear
+          count
+          meanScore
+          minutesWatched
+        }
+        scores(sort: ID) {
+          score
+          count
+          meanScore
+          minutesWatched
         }
       }
     }
@@ -101,6 +322,79 @@ mutation UpdateProgress($mediaId: Int!, $progress: Int!, $status: MediaListStatu
 }
 `
 
+// toggleFavouriteMutation adds an anime to the viewer's favourites or
+// removes it
+const toggleFavouriteMutation = `
+mutation ToggleFavourite($animeId: Int!) {
+  ToggleFavourite(animeId: $animeId) {
+    anime {
+      pageInfo {
+        total
+      }
+    }
+  }
+}
+`
+
+// getActivityQuery retrieves a page of list activity from users the viewer follows
+const getActivityQuery = `
+query GetActivity($page: Int) {
+  Page(page: $page, perPage: 25) {
+    pageInfo {
+      total
+      currentPage
+      lastPage
+      hasNextPage
+    }
+    activities(isFollowing: true, type: ANIME_LIST, sort: ID_DESC) {
+      ... on ListActivity {
+        id
+        status
+        progress
+        createdAt
+        user {
+          id
+          name
+        }
+        media {
+          id
+          title {
+            romaji
+            english
+            native
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+// customListsQuery retrieves the names of the viewer's custom anime lists
+const customListsQuery = `
+query GetCustomLists {
+  Viewer {
+    mediaListOptions {
+      animeList {
+        customLists
+      }
+    }
+  }
+}
+`
+
+// saveCustomListsMutation sets which custom lists an anime is on. Status is
+// only needed when the anime isn't on the viewer's list yet.
+const saveCustomListsMutation = `
+mutation SaveCustomLists($mediaId: Int!, $customLists: [String], $status: MediaListStatus) {
+  SaveMediaListEntry(mediaId: $mediaId, customLists: $customLists, status: $status) {
+    id
+    status
+    customLists
+  }
+}
+`
+
 // viewerQuery retrieves the authenticated user's information
 const viewerQuery = `
 query GetViewer {
@@ -1,5 +1,11 @@
 package anilist
 
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
 // Title represents anime title in multiple languages
 type Title struct {
 	Romaji  string `json:"romaji"`
@@ -32,6 +38,21 @@ type AiringSchedule struct {
 	TimeUntilAiring int   `json:"timeUntilAiring"`
 }
 
+// AiringScheduleConnection wraps airing schedule nodes
+type AiringScheduleConnection struct {
+	Nodes []AiringSchedule `json:"nodes"`
+}
+
+// AiringAt returns when the given episode airs, if it is scheduled
+func (c AiringScheduleConnection) AiringAt(episode int) (int64, bool) {
+	for _, n := range c.Nodes {
+		if n.Episode == episode {
+			return n.AiringAt, true
+		}
+	}
+	return 0, false
+}
+
 // PageInfo holds pagination data from AniList's Page queries
 type PageInfo struct {
 	Total       int  `json:"total"`
@@ -56,21 +77,175 @@ type Media struct {
 	Source             string            `json:"source"`     // MANGA, LIGHT_NOVEL, VISUAL_NOVEL, etc.
 	Studios            StudioConnection  `json:"studios"`
 	NextAiringEpisode  *AiringSchedule   `json:"nextAiringEpisode"`
+	Type               string            `json:"type"` // ANIME or MANGA
+	Synonyms           []string          `json:"synonyms"`
+	Relations          MediaConnection   `json:"relations"`
+	AiringSchedule     AiringScheduleConnection `json:"airingSchedule"` // upcoming episodes only
+	MediaListEntry     *MediaList        `json:"mediaListEntry"` // the viewer's entry, nil if not on their list
+	IsFavourite        bool              `json:"isFavourite"`    // always false without a token
+	StreamingEpisodes  []StreamingEpisode `json:"streamingEpisodes"`
+	CoverImage         CoverImage        `json:"coverImage"`
+	Recommendations    RecommendationConnection `json:"recommendations"`
+	Stats              MediaStats        `json:"stats"`
+}
+
+// MediaStats holds how users scored and list an anime
+type MediaStats struct {
+	ScoreDistribution  []ScoreDistribution  `json:"scoreDistribution"`
+	StatusDistribution []StatusDistribution `json:"statusDistribution"`
+}
+
+// ScoreDistribution is how many users gave a score (10 to 100)
+type ScoreDistribution struct {
+	Score  int `json:"score"`
+	Amount int `json:"amount"`
+}
+
+// StatusDistribution is how many users have an anime in a list status
+type StatusDistribution struct {
+	Status string `json:"status"`
+	Amount int    `json:"amount"`
+}
+
+// Name is a character's or staff member's name
+type Name struct {
+	Full   string `json:"full"`
+	Native string `json:"native"`
+}
+
+// Character is a character appearing in an anime
+type Character struct {
+	ID   int  `json:"id"`
+	Name Name `json:"name"`
+}
+
+// Staff is a person credited on an anime, including voice actors
+type Staff struct {
+	ID         int    `json:"id"`
+	Name       Name   `json:"name"`
+	LanguageV2 string `json:"languageV2"` // for voice actors, e.g. "Japanese"
+}
+
+// CharacterEdge is a character's role in an anime and who voices them
+type CharacterEdge struct {
+	Role        string  `json:"role"` // MAIN, SUPPORTING or BACKGROUND
+	Node        Character `json:"node"`
+	VoiceActors []Staff `json:"voiceActors"`
+}
+
+// CharacterConnection is one page of an anime's characters
+type CharacterConnection struct {
+	PageInfo PageInfo        `json:"pageInfo"`
+	Edges    []CharacterEdge `json:"edges"`
+}
+
+// StaffEdge is a staff member's role on an anime
+type StaffEdge struct {
+	Role string `json:"role"` // free text, e.g. "Director"
+	Node Staff  `json:"node"`
+}
+
+// StaffConnection is one page of an anime's staff
+type StaffConnection struct {
+	PageInfo PageInfo    `json:"pageInfo"`
+	Edges    []StaffEdge `json:"edges"`
+}
+
+// Recommendation is an anime users voted as similar to another
+type Recommendation struct {
+	Rating              int    `json:"rating"`
+	MediaRecommendation *Media `json:"mediaRecommendation"` // nil if the entry was deleted
+}
+
+// RecommendationConnection wraps recommendation nodes
+type RecommendationConnection struct {
+	Nodes []Recommendation `json:"nodes"`
+}
+
+// CoverImage holds the URL of an anime's cover
+type CoverImage struct {
+	Large string `json:"large"`
+	Color string `json:"color"` // average colour as #rrggbb, may be empty
+}
+
+// StreamingEpisode is an episode listed on a legal streaming site
+type StreamingEpisode struct {
+	Title     string `json:"title"` // usually "Episode 12 - The Journey's End"
+	Thumbnail string `json:"thumbnail"`
+	URL       string `json:"url"`
+	Site      string `json:"site"`
+}
+
+// episodeTitleRe splits streaming episode titles into number and name
+var episodeTitleRe = regexp.MustCompile(`(?i)^(?:episode|ep\.?)\s*(\d+)\s*(?:[-–—:.]\s*(.*))?$`)
+
+// EpisodeTitles maps episode numbers to the streaming episodes that name
+// them. Titles without an episode number are skipped, and the first listing
+// of an episode wins.
+func (m Media) EpisodeTitles() map[int]StreamingEpisode {
+	eps := make(map[int]StreamingEpisode)
+	for _, se := range m.StreamingEpisodes {
+		match := episodeTitleRe.FindStringSubmatch(strings.TrimSpace(se.Title))
+		if match == nil {
+			continue
+		}
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		if _, ok := eps[n]; ok {
+			continue
+		}
+		se.Title = strings.TrimSpace(match[2])
+		eps[n] = se
+	}
+	return eps
+}
+
+// MediaEdge links a media to a related one
+type MediaEdge struct {
+	RelationType string `json:"relationType"` // PREQUEL, SEQUEL, PARENT, SIDE_STORY, etc.
+	Node         Media  `json:"node"`
+}
+
+// MediaConnection wraps relation edges
+type MediaConnection struct {
+	Edges []MediaEdge `json:"edges"`
+}
+
+// Prequel returns the series entry this one directly continues, if any.
+// Movies, OVAs and specials are skipped so the result is a previous season.
+func (m Media) Prequel() (Media, bool) {
+	for _, e := range m.Relations.Edges {
+		if e.RelationType != "PREQUEL" || e.Node.Type != "ANIME" {
+			continue
+		}
+		switch e.Node.Format {
+		case "TV", "TV_SHORT", "ONA":
+			return e.Node, true
+		}
+	}
+	return Media{}, false
 }
 
 // MediaList represents a user's anime list entry
 type MediaList struct {
 	ID        int    `json:"id"`
-	Status    string `json:"status"`  // CURRENT, PLANNING, COMPLETED, DROPPED, PAUSED
+	Status    string `json:"status"`  // CURRENT, PLANNING, COMPLETED, DROPPED, PAUSED, REPEATING
 	Progress  int    `json:"progress"` // number of episodes watched
 	Score     int    `json:"score"`
+	UpdatedAt int64  `json:"updatedAt"` // unix seconds
+	CustomLists map[string]bool `json:"customLists"` // custom list name to membership, in details only
 	Media     Media  `json:"media"`
 }
 
-// MediaListGroup represents a group of media list entries by status
+// MediaListGroup represents a group of media list entries, either by status
+// or a custom list
 type MediaListGroup struct {
-	Status  string      `json:"status"`
-	Entries []MediaList `json:"entries"`
+	Name         string      `json:"name"` // e.g. "Watching", or the custom list's name
+	IsCustomList bool        `json:"isCustomList"`
+	Status       string      `json:"status"` // empty for custom lists
+	Entries      []MediaList `json:"entries"`
 }
 
 // MediaListCollection wraps list groups
@@ -78,8 +253,44 @@ type MediaListCollection struct {
 	Lists []MediaListGroup `json:"lists"`
 }
 
+// UserStatistics summarises a user's anime list
+type UserStatistics struct {
+	Count           int             `json:"count"`
+	EpisodesWatched int             `json:"episodesWatched"`
+	MinutesWatched  int             `json:"minutesWatched"`
+	MeanScore       float64         `json:"meanScore"`
+	Genres          []UserStatistic `json:"genres"`
+	Formats         []UserStatistic `json:"formats"`
+	Studios         []UserStatistic `json:"studios"`
+	ReleaseYears    []UserStatistic `json:"releaseYears"`
+	Scores          []UserStatistic `json:"scores"`
+}
+
+// UserStatistic is one row of a statistics breakdown. Only the field naming
+// its breakdown (genre, format, studio, year or score) is set.
+type UserStatistic struct {
+	Genre          string  `json:"genre"`
+	Format         string  `json:"format"`
+	Studio         *Studio `json:"studio"`
+	ReleaseYear    int     `json:"releaseYear"`
+	Score          int     `json:"score"`
+	Count          int     `json:"count"`
+	MeanScore      float64 `json:"meanScore"`
+	MinutesWatched int     `json:"minutesWatched"`
+}
+
 // User represents an AniList user
 type User struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 }
+
+// ListActivity is a list update posted by a user, e.g. "watched episode 3"
+type ListActivity struct {
+	ID        int    `json:"id"`
+	Status    string `json:"status"`   // free text: "watched episode", "completed", "plans to watch", etc.
+	Progress  string `json:"progress"` // episode or range such as "4 - 6", may be empty
+	CreatedAt int64  `json:"createdAt"`
+	User      User   `json:"user"`
+	Media     Media  `json:"media"`
+}
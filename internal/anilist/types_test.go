@@ -0,0 +1,79 @@
+package anilist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMedia_EpisodeTitles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		titles []string
+		want   map[int]string
+	}{
+		{
+			name:   "usual format",
+			titles: []string{"Episode 12 - The Journey's End", "Episode 1 - The End of the Journey"},
+			want:   map[int]string{1: "The End of the Journey", 12: "The Journey's End"},
+		},
+		{
+			name:   "other separators and abbreviations",
+			titles: []string{"Ep. 3: Killing Magic", "EPISODE 4 – The Land Where Souls Rest", "ep5. Phantoms of the Dead", "Episode 6 — A Mage's Power"},
+			want:   map[int]string{3: "Killing Magic", 4: "The Land Where Souls Rest", 5: "Phantoms of the Dead", 6: "A Mage's Power"},
+		},
+		{
+			name:   "separator kept inside the name",
+			titles: []string{"Episode 7 - Part 2: Like a Fairy Tale"},
+			want:   map[int]string{7: "Part 2: Like a Fairy Tale"},
+		},
+		{
+			name:   "number without a name",
+			titles: []string{"Episode 8", "  Episode 9 -  "},
+			want:   map[int]string{8: "", 9: ""},
+		},
+		{
+			name:   "titles without a number are skipped",
+			titles: []string{"Recap Special", "The Journey's End", "Episode - Finale", ""},
+			want:   map[int]string{},
+		},
+		{
+			name:   "first listing of a duplicate wins",
+			titles: []string{"Episode 2 - It Didn't Have to Be Magic...", "Episode 2 - Some Other Site's Title", "Episode 02 - Zero Padded"},
+			want:   map[int]string{2: "It Didn't Have to Be Magic..."},
+		},
+		{
+			name:   "empty list",
+			titles: nil,
+			want:   map[int]string{},
+		},
+	}
+	for _, tt := range tests {
+		var m Media
+		for _, title := range tt.titles {
+			m.StreamingEpisodes = append(m.StreamingEpisodes, StreamingEpisode{Title: title, URL: "https://example.com/" + title})
+		}
+		eps := m.EpisodeTitles()
+		got := make(map[int]string, len(eps))
+		for n, se := range eps {
+			got[n] = se.Title
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: EpisodeTitles = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMedia_EpisodeTitlesKeepsListing(t *testing.T) {
+	t.Parallel()
+
+	m := Media{StreamingEpisodes: []StreamingEpisode{
+		{Title: "Episode 12 - The Journey's End", Thumbnail: "thumb.jpg", URL: "https://example.com/12", Site: "Crunchyroll"},
+		{Title: "Episode 12 - The Journey's End", URL: "https://example.com/other", Site: "Other"},
+	}}
+	want := StreamingEpisode{Title: "The Journey's End", Thumbnail: "thumb.jpg", URL: "https://example.com/12", Site: "Crunchyroll"}
+	if got := m.EpisodeTitles()[12]; got != want {
+		t.Fatalf("EpisodeTitles()[12] = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,312 @@
+// Package cache keeps API responses in memory and on disk so that repeated
+// lookups are instant and survive restarts.
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rayanxn/ani-tui/internal/fsutil"
+)
+
+// Default bounds of a cache. Entries older than maxAge are deleted when the
+// cache is opened; past maxDiskBytes the oldest files go first. The memory
+// copy keeps the most recently used entries up to maxMemBytes.
+const (
+	maxAge       = 30 * 24 * time.Hour
+	maxDiskBytes = 256 << 20
+	maxMemBytes  = 32 << 20
+)
+
+// Cache stores responses grouped by namespace (typically one per API
+// endpoint). Entries don't expire while the cache is open: callers decide
+// how old is too old, which lets them serve stale data while refreshing it.
+//
+// A nil *Cache is valid and stores nothing.
+type Cache struct {
+	dir string
+	now func() time.Time
+
+	maxAge  time.Duration
+	maxDisk int64
+	maxMem  int64
+
+	mu       sync.Mutex
+	mem      map[string]*list.Element // keyed by namespace + "/" + file name
+	lru      *list.List               // of *memEntry, most recently used first
+	memSize  int64
+	diskSize int64
+}
+
+// entry is one cached response, as kept in memory and written to disk.
+type entry struct {
+	Stored time.Time       `json:"stored"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// memEntry is an entry in the memory copy.
+type memEntry struct {
+	key string
+	entry
+}
+
+// Open returns a cache rooted at dir, creating it if needed, and deletes
+// entries past its age and size bounds.
+func Open(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	c := &Cache{
+		dir:     dir,
+		now:     time.Now,
+		maxAge:  maxAge,
+		maxDisk: maxDiskBytes,
+		maxMem:  maxMemBytes,
+		mem:     make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneLocked(c.maxDisk)
+	return c, nil
+}
+
+// Get returns the value stored with Put for key in ns and how old it is.
+func (c *Cache) Get(ns, key string) (data []byte, age time.Duration, ok bool) {
+	if c == nil {
+		return nil, 0, false
+	}
+	e, ok := c.get(ns, fileName(key, ".json"), func(path string) (entry, bool) {
+		var e entry
+		raw, err := os.ReadFile(path)
+		if err != nil || json.Unmarshal(raw, &e) != nil {
+			return entry{}, false
+		}
+		return e, true
+	})
+	if !ok {
+		return nil, 0, false
+	}
+	return e.Data, c.now().Sub(e.Stored), true
+}
+
+// GetBytes returns the value stored with PutBytes for key in ns and how old
+// it is.
+func (c *Cache) GetBytes(ns, key string) (data []byte, age time.Duration, ok bool) {
+	if c == nil {
+		return nil, 0, false
+	}
+	e, ok := c.get(ns, fileName(key, ".bin"), func(path string) (entry, bool) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return entry{}, false
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return entry{}, false
+		}
+		return entry{Stored: info.ModTime(), Data: raw}, true
+	})
+	if !ok {
+		return nil, 0, false
+	}
+	return e.Data, c.now().Sub(e.Stored), true
+}
+
+// get returns the entry named name in ns from memory, or from disk with
+// load.
+func (c *Cache) get(ns, name string, load func(path string) (entry, bool)) (entry, bool) {
+	key := ns + "/" + name
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.mem[key]; ok {
+		c.lru.MoveToFront(el)
+		return el.Value.(*memEntry).entry, true
+	}
+	e, ok := load(filepath.Join(c.dir, ns, name))
+	if !ok {
+		return entry{}, false
+	}
+	c.rememberLocked(key, e)
+	return e, true
+}
+
+// Put stores data, which must be valid JSON, for key in ns.
+func (c *Cache) Put(ns, key string, data []byte) error {
+	if c == nil {
+		return nil
+	}
+	e := entry{Stored: c.now(), Data: data}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal cache entry: %w", err)
+	}
+	return c.put(ns, fileName(key, ".json"), e, raw)
+}
+
+// PutBytes stores data of any kind, such as an image, for key in ns. It is
+// written to disk as is; its age comes from the file's modification time.
+func (c *Cache) PutBytes(ns, key string, data []byte) error {
+	if c == nil {
+		return nil
+	}
+	return c.put(ns, fileName(key, ".bin"), entry{Stored: c.now(), Data: data}, data)
+}
+
+// put records e in memory and writes raw to the file named name in ns.
+func (c *Cache) put(ns, name string, e entry, raw []byte) error {
+	key := ns + "/" + name
+	path := filepath.Join(c.dir, ns, name)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forgetLocked(key)
+	c.rememberLocked(key, e)
+
+	if info, err := os.Stat(path); err == nil {
+		c.diskSize -= info.Size()
+	}
+	if err := fsutil.WriteFileAtomic(path, raw, 0o600); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	// Bytes entries are aged by modification time, which must match the
+	// cache's clock rather than the file system's.
+	_ = os.Chtimes(path, e.Stored, e.Stored)
+	c.diskSize += int64(len(raw))
+	if c.diskSize > c.maxDisk {
+		// Leave headroom so the next few writes don't prune again.
+		c.pruneLocked(c.maxDisk * 3 / 4)
+	}
+	return nil
+}
+
+// Delete drops the entry stored for key in ns, if any.
+func (c *Cache) Delete(ns, key string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range []string{fileName(key, ".json"), fileName(key, ".bin")} {
+		c.forgetLocked(ns + "/" + name)
+		path := filepath.Join(c.dir, ns, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("delete cache entry: %w", err)
+		}
+		c.diskSize -= info.Size()
+	}
+	return nil
+}
+
+// Invalidate drops every entry in ns.
+func (c *Cache) Invalidate(ns string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prefix := ns + "/"
+	for k := range c.mem {
+		if strings.HasPrefix(k, prefix) {
+			c.forgetLocked(k)
+		}
+	}
+	for _, f := range c.filesLocked(filepath.Join(c.dir, ns)) {
+		c.diskSize -= f.size
+	}
+	if err := os.RemoveAll(filepath.Join(c.dir, ns)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("invalidate cache: %w", err)
+	}
+	return nil
+}
+
+// rememberLocked adds e to the memory copy, evicting the least recently used
+// entries past maxMem. Callers must hold c.mu.
+func (c *Cache) rememberLocked(key string, e entry) {
+	c.mem[key] = c.lru.PushFront(&memEntry{key: key, entry: e})
+	c.memSize += int64(len(e.Data))
+	for c.memSize > c.maxMem && c.lru.Len() > 1 {
+		c.forgetLocked(c.lru.Back().Value.(*memEntry).key)
+	}
+}
+
+// forgetLocked drops key from the memory copy. Callers must hold c.mu.
+func (c *Cache) forgetLocked(key string) {
+	el, ok := c.mem[key]
+	if !ok {
+		return
+	}
+	c.lru.Remove(el)
+	delete(c.mem, key)
+	c.memSize -= int64(len(el.Value.(*memEntry).Data))
+}
+
+// cacheFile is a file on disk, as seen by pruneLocked.
+type cacheFile struct {
+	path     string
+	size     int64
+	modified time.Time
+}
+
+// filesLocked lists the files under dir. Callers must hold c.mu.
+func (c *Cache) filesLocked(dir string) []cacheFile {
+	var files []cacheFile
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files = append(files, cacheFile{path: path, size: info.Size(), modified: info.ModTime()})
+		}
+		return nil
+	})
+	return files
+}
+
+// pruneLocked deletes files older than maxAge, then the oldest remaining
+// files until the cache fits in limit bytes, and recounts diskSize. Files
+// are written once per Put, so their modification time is when they were
+// stored. Callers must hold c.mu.
+func (c *Cache) pruneLocked(limit int64) {
+	files := c.filesLocked(c.dir)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modified.Before(files[j].modified)
+	})
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	cutoff := c.now().Add(-c.maxAge)
+	for _, f := range files {
+		if !f.modified.Before(cutoff) && total <= limit {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+	c.diskSize = total
+}
+
+// fileName maps a key of any length and content to a safe file name with
+// the given extension.
+func fileName(key, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + ext
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache_PutGetAndReload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	// Recent enough to survive pruning on reopen.
+	now := time.Now().Truncate(time.Second)
+	c.now = func() time.Time { return now }
+
+	if _, _, ok := c.Get("details", "1"); ok {
+		t.Fatal("empty cache should miss")
+	}
+	if err := c.Put("details", "1", []byte(`{"id":1}`)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	data, age, ok := c.Get("details", "1")
+	if !ok || string(data) != `{"id":1}` || age != time.Hour {
+		t.Fatalf("Get = %s, %v, %v; want {\"id\":1}, 1h, true", data, age, ok)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	reopened.now = c.now
+	data, age, ok = reopened.Get("details", "1")
+	if !ok || string(data) != `{"id":1}` || age != time.Hour {
+		t.Fatalf("Get after reopen = %s, %v, %v", data, age, ok)
+	}
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for _, ns := range []string{"list", "details"} {
+		if err := c.Put(ns, "k", []byte(`[]`)); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	if err := c.Invalidate("list"); err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+	if _, _, ok := c.Get("list", "k"); ok {
+		t.Fatal("invalidated entry still in memory")
+	}
+	reopened, _ := Open(dir)
+	if _, _, ok := reopened.Get("list", "k"); ok {
+		t.Fatal("invalidated entry still on disk")
+	}
+	if _, _, ok := reopened.Get("details", "k"); !ok {
+		t.Fatal("other namespaces should survive invalidation")
+	}
+}
+
+func TestCache_NilIsNoop(t *testing.T) {
+	t.Parallel()
+
+	var c *Cache
+	if err := c.Put("ns", "k", []byte(`1`)); err != nil {
+		t.Fatalf("Put on nil cache: %v", err)
+	}
+	if _, _, ok := c.Get("ns", "k"); ok {
+		t.Fatal("nil cache should miss")
+	}
+	if err := c.Invalidate("ns"); err != nil {
+		t.Fatalf("Invalidate on nil cache: %v", err)
+	}
+}
+
+func TestCache_BytesAgeFromFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	// Recent enough to survive pruning on reopen; whole seconds so file
+	// modification times hold it exactly.
+	now := time.Now().Truncate(time.Second)
+	c.now = func() time.Time { return now }
+	if err := c.PutBytes("images", "cover", []byte{0xff, 0xd8}); err != nil {
+		t.Fatalf("PutBytes: %v", err)
+	}
+	if _, _, ok := c.Get("images", "cover"); ok {
+		t.Fatal("bytes entries should not be readable as JSON entries")
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	reopened.now = func() time.Time { return now.Add(time.Hour) }
+	data, age, ok := reopened.GetBytes("images", "cover")
+	if !ok || len(data) != 2 || age != time.Hour {
+		t.Fatalf("GetBytes after reopen = %x, %v, %v; want ffd8, 1h, true", data, age, ok)
+	}
+}
+
+func TestCache_OpenPrunesOldAndOversized(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	now := time.Now()
+	for i, key := range []string{"ancient", "old", "new"} {
+		c.now = func() time.Time { return now.Add(time.Duration(i-2) * 40 * 24 * time.Hour / 2) }
+		if err := c.PutBytes("images", key, make([]byte, 100)); err != nil {
+			t.Fatalf("PutBytes: %v", err)
+		}
+	}
+	// ancient is 40 days old, old 20 days and new is from now.
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, _, ok := reopened.GetBytes("images", "ancient"); ok {
+		t.Fatal("entry past the age limit survived reopening")
+	}
+	if _, _, ok := reopened.GetBytes("images", "old"); !ok {
+		t.Fatal("entry within the age limit was pruned")
+	}
+
+	reopened.maxDisk = 150
+	reopened.mu.Lock()
+	reopened.pruneLocked(reopened.maxDisk)
+	reopened.mu.Unlock()
+	if _, err := os.Stat(filepath.Join(dir, "images", fileName("old", ".bin"))); !os.IsNotExist(err) {
+		t.Fatal("oldest entry should go first when over the size limit")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "images", fileName("new", ".bin"))); err != nil {
+		t.Fatalf("newest entry was pruned: %v", err)
+	}
+}
+
+func TestCache_MemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	c, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	c.maxMem = 20
+	_ = c.Put("ns", "a", []byte(`"0123456789"`))
+	_ = c.Put("ns", "b", []byte(`"0123456"`))
+	c.Get("ns", "a") // a is now the most recently used
+	_ = c.Put("ns", "c", []byte(`"0123"`))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.mem["ns/"+fileName("b", ".json")]; ok {
+		t.Fatal("least recently used entry should have been evicted")
+	}
+	if _, ok := c.mem["ns/"+fileName("a", ".json")]; !ok {
+		t.Fatal("recently read entry was evicted")
+	}
+	if c.memSize > c.maxMem {
+		t.Fatalf("memory copy holds %d bytes, over the %d limit", c.memSize, c.maxMem)
+	}
+}
@@ -6,17 +6,66 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/rayanxn/ani-tui/internal/fsutil"
 )
 
 const appName = "ani-tui"
 
 // Config holds all persistent application settings.
 type Config struct {
-	AniListToken    string `json:"anilist_token,omitempty"`
-	AniListUserID   int    `json:"anilist_user_id,omitempty"`
-	DownloadDir     string `json:"download_dir,omitempty"`
-	MpvPath         string `json:"mpv_path,omitempty"`
+	AniListToken     string `json:"anilist_token,omitempty"`
+	AniListUserID    int    `json:"anilist_user_id,omitempty"`
+	DownloadDir      string `json:"download_dir,omitempty"`
+	MpvPath          string `json:"mpv_path,omitempty"`
 	PreferredQuality string `json:"preferred_quality,omitempty"`
+
+	// Download queue limits. MaxActiveDownloads <= 0 uses the default (2);
+	// DiskQuotaGB 0 means unlimited.
+	MaxActiveDownloads int     `json:"max_active_downloads,omitempty"`
+	DiskQuotaGB        float64 `json:"disk_quota_gb,omitempty"`
+
+	// Bandwidth caps in KiB/s; 0 means unlimited. ThrottleKBps applies while
+	// throttling is toggled on from the player (0 uses 256).
+	MaxDownloadKBps int `json:"max_download_kbps,omitempty"`
+	MaxUploadKBps   int `json:"max_upload_kbps,omitempty"`
+	ThrottleKBps    int `json:"throttle_kbps,omitempty"`
+
+	// Seeding policy for finished downloads. Seeding stops at whichever of
+	// SeedRatio or SeedTimeMinutes is reached first; 0 disables that limit.
+	Seed            bool    `json:"seed,omitempty"`
+	SeedRatio       float64 `json:"seed_ratio,omitempty"`
+	SeedTimeMinutes int     `json:"seed_time_minutes,omitempty"`
+
+	// Ranking orders torrent results and decides what auto-pick plays.
+	Ranking Ranking `json:"ranking"`
+
+	// BingeMode starts the next episode after a short countdown when one
+	// finishes playing. It can also be toggled from the player.
+	BingeMode bool `json:"binge_mode,omitempty"`
+
+	// ListenPort for incoming peer connections; 0 uses the default (42069).
+	ListenPort int `json:"listen_port,omitempty"`
+
+	// CoverArt picks how cover images are drawn: "kitty", "sixel", "blocks"
+	// or "off". Empty detects what the terminal supports.
+	CoverArt string `json:"cover_art,omitempty"`
+
+	// Offline forces offline mode for this session (--offline); it is never
+	// saved.
+	Offline bool `json:"-"`
+}
+
+// Ranking is the user's release preference profile. Lists are ordered from
+// most to least preferred; empty fields apply no preference.
+type Ranking struct {
+	PreferredGroups []string `json:"preferred_groups,omitempty"`
+	BlockedGroups   []string `json:"blocked_groups,omitempty"`
+	Resolutions     []string `json:"resolutions,omitempty"` // e.g. ["1080p", "720p"]
+	Codecs          []string `json:"codecs,omitempty"`      // "HEVC", "AVC", "AV1"
+	MinSeeders      int      `json:"min_seeders,omitempty"`
+	TrustedOnly     bool     `json:"trusted_only,omitempty"`
+	AllowRemakes    bool     `json:"allow_remakes"`
 }
 
 // configDir returns the XDG config directory for the app.
@@ -28,6 +77,39 @@ func configDir() (string, error) {
 	return filepath.Join(base, appName), nil
 }
 
+// DataDir returns the XDG data directory for the app
+// ($XDG_DATA_HOME/ani-tui, falling back to ~/.local/share/ani-tui).
+func DataDir() (string, error) {
+	if base := os.Getenv("XDG_DATA_HOME"); base != "" {
+		return filepath.Join(base, appName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("data dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", appName), nil
+}
+
+// DefaultDownloadDir returns the directory used for torrent data when
+// DownloadDir is not configured.
+func DefaultDownloadDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "downloads"), nil
+}
+
+// CacheDir returns the directory for cached API responses
+// (os.UserCacheDir()/ani-tui).
+func CacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cache dir: %w", err)
+	}
+	return filepath.Join(base, appName), nil
+}
+
 // configPath returns the full path to the config file.
 func configPath() (string, error) {
 	dir, err := configDir()
@@ -37,8 +119,13 @@ func configPath() (string, error) {
 	return filepath.Join(dir, "config.json"), nil
 }
 
-// Load reads the config from disk. Returns a zero-value Config if the file
-// doesn't exist yet.
+// defaults returns the settings used for fields missing from the config file.
+func defaults() Config {
+	return Config{Ranking: Ranking{AllowRemakes: true}}
+}
+
+// Load reads the config from disk. Returns the defaults if the file doesn't
+// exist yet.
 func Load() (Config, error) {
 	path, err := configPath()
 	if err != nil {
@@ -48,12 +135,12 @@ func Load() (Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return Config{}, nil
+			return defaults(), nil
 		}
 		return Config{}, fmt.Errorf("read config: %w", err)
 	}
 
-	var cfg Config
+	cfg := defaults()
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return Config{}, fmt.Errorf("parse config: %w", err)
 	}
@@ -63,33 +150,12 @@ func Load() (Config, error) {
 // Save writes the config to disk using an atomic write (write to temp file,
 // then rename) to avoid corruption.
 func Save(cfg Config) error {
-	dir, err := configDir()
-	if err != nil {
-		return err
-	}
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("create config dir: %w", err)
-	}
-
-	data, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal config: %w", err)
-	}
-	data = append(data, '\n')
-
 	path, err := configPath()
 	if err != nil {
 		return err
 	}
-
-	// Atomic write: write to temp file in the same directory, then rename.
-	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, data, 0o600); err != nil {
-		return fmt.Errorf("write temp config: %w", err)
-	}
-	if err := os.Rename(tmp, path); err != nil {
-		os.Remove(tmp)
-		return fmt.Errorf("rename config: %w", err)
+	if err := fsutil.WriteJSONAtomic(path, cfg); err != nil {
+		return fmt.Errorf("save config: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,209 @@
+package downloads
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rayanxn/ani-tui/internal/config"
+	"github.com/rayanxn/ani-tui/internal/fsutil"
+)
+
+// Entry records one episode file stored on disk.
+type Entry struct {
+	MediaID   int       `json:"media_id"`
+	Title     string    `json:"title"`
+	Episode   int       `json:"episode"`
+	InfoHash  string    `json:"info_hash,omitempty"`
+	Path      string    `json:"path"` // absolute path of the video file
+	Size      int64     `json:"size"`
+	Complete  bool      `json:"complete"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Index is a persistent, concurrency-safe record of downloaded episodes.
+type Index struct {
+	mu      sync.Mutex
+	path    string
+	entries []Entry
+	version atomic.Uint64 // bumped on every change
+}
+
+// DefaultPath returns the index location inside the app data directory.
+func DefaultPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "downloads.json"), nil
+}
+
+// Open loads the index stored at path. A missing file yields an empty index.
+func Open(path string) (*Index, error) {
+	idx := &Index{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return idx, nil
+		}
+		return nil, fmt.Errorf("read downloads index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &idx.entries); err != nil {
+		return nil, fmt.Errorf("parse downloads index: %w", err)
+	}
+	return idx, nil
+}
+
+// Entries returns a copy of all entries sorted by title, then episode.
+func (ix *Index) Entries() []Entry {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	out := make([]Entry, len(ix.entries))
+	copy(out, ix.entries)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Title != out[j].Title {
+			return out[i].Title < out[j].Title
+		}
+		return out[i].Episode < out[j].Episode
+	})
+	return out
+}
+
+// Lookup returns a completed entry for the given episode whose file still
+// exists on disk.
+func (ix *Index) Lookup(mediaID, episode int) (Entry, bool) {
+	for _, e := range ix.completed(mediaID) {
+		if e.Episode != episode {
+			continue
+		}
+		if _, err := os.Stat(e.Path); err == nil {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Episodes returns the completed episodes of mediaID whose files still exist
+// on disk.
+func (ix *Index) Episodes(mediaID int) map[int]bool {
+	episodes := make(map[int]bool)
+	for _, e := range ix.completed(mediaID) {
+		if episodes[e.Episode] {
+			continue
+		}
+		if _, err := os.Stat(e.Path); err == nil {
+			episodes[e.Episode] = true
+		}
+	}
+	return episodes
+}
+
+// completed returns the completed entries of mediaID. Their files are
+// checked by the caller, outside the lock.
+func (ix *Index) completed(mediaID int) []Entry {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	var out []Entry
+	for _, e := range ix.entries {
+		if e.MediaID == mediaID && e.Complete {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Version changes whenever the index does, so views can tell when what they
+// derived from it is out of date.
+func (ix *Index) Version() uint64 {
+	return ix.version.Load()
+}
+
+// Put inserts or replaces the entry with the same path and persists the index.
+func (ix *Index) Put(e Entry) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if e.UpdatedAt.IsZero() {
+		e.UpdatedAt = time.Now()
+	}
+
+	replaced := false
+	for i := range ix.entries {
+		if ix.entries[i].Path == e.Path {
+			ix.entries[i] = e
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		ix.entries = append(ix.entries, e)
+	}
+	return ix.saveLocked()
+}
+
+// Remove deletes the entry for path and its file on disk, then persists the
+// index. Directories left empty between the file and root are removed too;
+// root itself is kept. A file that is already gone is not an error. Callers
+// must first drop the file's torrent so it is not written to or seeded.
+func (ix *Index) Remove(path, root string) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete download: %w", err)
+	}
+	// Leftover partial data from an unfinished download.
+	os.Remove(path + ".part")
+	pruneEmptyDirs(filepath.Dir(path), root)
+
+	kept := ix.entries[:0]
+	for _, e := range ix.entries {
+		if e.Path != path {
+			kept = append(kept, e)
+		}
+	}
+	ix.entries = kept
+	return ix.saveLocked()
+}
+
+// pruneEmptyDirs removes dir and its parents while they are empty, stopping
+// at root. Nothing is removed when dir is not inside root.
+func pruneEmptyDirs(dir, root string) {
+	if root == "" {
+		return
+	}
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root; dir = filepath.Dir(dir) {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+		// os.Remove fails on a directory that still has files in it.
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// saveLocked writes the index atomically. Callers must hold ix.mu.
+func (ix *Index) saveLocked() error {
+	ix.version.Add(1)
+	if ix.path == "" {
+		return nil
+	}
+	if err := fsutil.WriteJSONAtomic(ix.path, ix.entries); err != nil {
+		return fmt.Errorf("save downloads index: %w", err)
+	}
+	return nil
+}
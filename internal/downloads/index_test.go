@@ -0,0 +1,158 @@
+package downloads
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndex_PutLookupAndReload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	video := filepath.Join(dir, "show-01.mkv")
+	if err := os.WriteFile(video, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	indexPath := filepath.Join(dir, "downloads.json")
+	idx, err := Open(indexPath)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if err := idx.Put(Entry{MediaID: 1, Episode: 1, Path: video}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, ok := idx.Lookup(1, 1); ok {
+		t.Fatal("incomplete entry should not be returned by Lookup")
+	}
+
+	if err := idx.Put(Entry{MediaID: 1, Episode: 1, Path: video, Complete: true}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	reloaded, err := Open(indexPath)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if n := len(reloaded.Entries()); n != 1 {
+		t.Fatalf("expected 1 entry after upsert, got %d", n)
+	}
+	e, ok := reloaded.Lookup(1, 1)
+	if !ok || e.Path != video {
+		t.Fatalf("Lookup = %+v, %v; want %q", e, ok, video)
+	}
+}
+
+func TestIndex_LookupSkipsMissingFiles(t *testing.T) {
+	t.Parallel()
+
+	idx, err := Open(filepath.Join(t.TempDir(), "downloads.json"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	_ = idx.Put(Entry{MediaID: 2, Episode: 3, Path: "/nonexistent/file.mkv", Complete: true})
+
+	if _, ok := idx.Lookup(2, 3); ok {
+		t.Fatal("expected Lookup to skip entry whose file is gone")
+	}
+}
+
+func TestIndex_RemoveDeletesFileAndEmptyDirs(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	show := filepath.Join(root, "Show", "Season 1")
+	if err := os.MkdirAll(show, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	video := filepath.Join(show, "show-02.mkv")
+	if err := os.WriteFile(video, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := Open(filepath.Join(root, "downloads.json"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	_ = idx.Put(Entry{MediaID: 1, Episode: 2, Path: video, Complete: true})
+
+	if err := idx.Remove(video, root); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(video); !os.IsNotExist(err) {
+		t.Fatalf("expected file to be deleted, stat err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Show")); !os.IsNotExist(err) {
+		t.Fatalf("expected empty show directory to be pruned, stat err = %v", err)
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Fatalf("root should be kept: %v", err)
+	}
+	if n := len(idx.Entries()); n != 0 {
+		t.Fatalf("expected empty index, got %d entries", n)
+	}
+}
+
+func TestIndex_RemoveKeepsNonEmptyDirs(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	show := filepath.Join(root, "Show")
+	if err := os.MkdirAll(show, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ep1 := filepath.Join(show, "show-01.mkv")
+	ep2 := filepath.Join(show, "show-02.mkv")
+	for _, p := range []string{ep1, ep2} {
+		if err := os.WriteFile(p, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idx, err := Open(filepath.Join(root, "downloads.json"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := idx.Remove(ep1, root); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(ep2); err != nil {
+		t.Fatalf("sibling episode should be kept: %v", err)
+	}
+}
+
+func TestIndex_EpisodesAndVersion(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	idx, err := Open(filepath.Join(dir, "downloads.json"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for ep := 1; ep <= 3; ep++ {
+		video := filepath.Join(dir, fmt.Sprintf("show-%02d.mkv", ep))
+		if ep != 2 { // episode 2 was deleted outside the app
+			if err := os.WriteFile(video, []byte("x"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_ = idx.Put(Entry{MediaID: 1, Episode: ep, Path: video, Complete: true})
+	}
+	_ = idx.Put(Entry{MediaID: 2, Episode: 1, Path: filepath.Join(dir, "other-01.mkv"), Complete: true})
+
+	got := idx.Episodes(1)
+	if len(got) != 2 || !got[1] || !got[3] {
+		t.Fatalf("Episodes(1) = %v, want 1 and 3", got)
+	}
+
+	before := idx.Version()
+	if err := idx.Remove(filepath.Join(dir, "show-03.mkv"), dir); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if idx.Version() == before {
+		t.Fatal("Version should change when an entry is removed")
+	}
+}
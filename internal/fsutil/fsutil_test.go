@@ -0,0 +1,59 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic_CreatesDirsAndReplaces(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "state.json")
+
+	if err := WriteFileAtomic(path, []byte("first"), 0o600); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("second"), 0o600); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "second" {
+		t.Fatalf("ReadFile = %q, %v; want second", data, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("perm = %v, want 0600", perm)
+	}
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("dir has %d entries, want only the file", len(entries))
+	}
+}
+
+func TestWriteJSONAtomic_MarshalErrorKeepsFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := WriteJSONAtomic(path, []int{1}); err != nil {
+		t.Fatalf("WriteJSONAtomic: %v", err)
+	}
+	if err := WriteJSONAtomic(path, func() {}); err == nil {
+		t.Fatal("expected an error marshalling a func")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "[\n  1\n]\n" {
+		t.Fatalf("ReadFile = %q, %v; want the earlier contents", data, err)
+	}
+}
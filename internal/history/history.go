@@ -0,0 +1,137 @@
+// Package history records how far into each episode local playback got, so
+// that episodes can be resumed and shown as partially watched, and logs
+// watch sessions for the stats view.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rayanxn/ani-tui/internal/config"
+	"github.com/rayanxn/ani-tui/internal/fsutil"
+)
+
+const (
+	// minResume is how far into an episode playback must get before it is
+	// worth resuming.
+	minResume = 30 * time.Second
+	// finishedPercent is how much of an episode counts as watched to the end;
+	// the last few minutes are usually credits.
+	finishedPercent = 90
+)
+
+// Position is where playback of one episode stopped.
+type Position struct {
+	MediaID   int           `json:"media_id"`
+	Episode   int           `json:"episode"`
+	Offset    time.Duration `json:"offset"`
+	Duration  time.Duration `json:"duration"` // zero if mpv never reported it
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// Percent returns how much of the episode was played, from 0 to 100, or 0
+// when the duration is unknown.
+func (p Position) Percent() float64 {
+	if p.Duration <= 0 {
+		return 0
+	}
+	return min(100, float64(p.Offset)/float64(p.Duration)*100)
+}
+
+// Partial reports whether the episode was started but not finished.
+func (p Position) Partial() bool {
+	return p.Offset >= minResume && p.Percent() < finishedPercent
+}
+
+// Store is a persistent, concurrency-safe record of playback positions.
+// A nil *Store records nothing.
+type Store struct {
+	mu        sync.Mutex
+	path      string
+	positions []Position
+}
+
+// DefaultPath returns the history location inside the app data directory.
+func DefaultPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.json"), nil
+}
+
+// Open loads the history stored at path. A missing file yields an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.positions); err != nil {
+		return nil, fmt.Errorf("parse history: %w", err)
+	}
+	return s, nil
+}
+
+// Record saves p, replacing the earlier position of the same episode.
+func (s *Store) Record(p Position) error {
+	if s == nil {
+		return nil
+	}
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, old := range s.positions {
+		if old.MediaID == p.MediaID && old.Episode == p.Episode {
+			s.positions[i] = p
+			return s.saveLocked()
+		}
+	}
+	s.positions = append(s.positions, p)
+	return s.saveLocked()
+}
+
+// Lookup returns the last recorded position in the given episode.
+func (s *Store) Lookup(mediaID, episode int) (Position, bool) {
+	if s == nil {
+		return Position{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.positions {
+		if p.MediaID == mediaID && p.Episode == episode {
+			return p, true
+		}
+	}
+	return Position{}, false
+}
+
+// Resume returns where to restart the given episode, or zero to play it
+// from the beginning.
+func (s *Store) Resume(mediaID, episode int) time.Duration {
+	if p, ok := s.Lookup(mediaID, episode); ok && p.Partial() {
+		return p.Offset
+	}
+	return 0
+}
+
+// saveLocked writes the store atomically. Callers must hold s.mu.
+func (s *Store) saveLocked() error {
+	if err := fsutil.WriteJSONAtomic(s.path, s.positions); err != nil {
+		return fmt.Errorf("save history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_RecordLookupAndReload(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if err := s.Record(Position{MediaID: 1, Episode: 2, Offset: time.Minute, Duration: 24 * time.Minute}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := s.Record(Position{MediaID: 1, Episode: 2, Offset: 10 * time.Minute, Duration: 24 * time.Minute}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	reloaded, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	p, ok := reloaded.Lookup(1, 2)
+	if !ok || p.Offset != 10*time.Minute {
+		t.Fatalf("Lookup = %+v, %v; want offset 10m", p, ok)
+	}
+	if _, ok := reloaded.Lookup(1, 3); ok {
+		t.Fatal("Lookup found an episode that was never played")
+	}
+	if got := reloaded.Resume(1, 2); got != 10*time.Minute {
+		t.Fatalf("Resume = %v, want 10m", got)
+	}
+}
+
+func TestPosition_Partial(t *testing.T) {
+	t.Parallel()
+
+	const ep = 24 * time.Minute
+	tests := []struct {
+		name string
+		pos  Position
+		want bool
+	}{
+		{"barely started", Position{Offset: 10 * time.Second, Duration: ep}, false},
+		{"halfway", Position{Offset: 12 * time.Minute, Duration: ep}, true},
+		{"in the credits", Position{Offset: 23 * time.Minute, Duration: ep}, false},
+		{"unknown duration", Position{Offset: 5 * time.Minute}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.pos.Partial(); got != tt.want {
+			t.Errorf("%s: Partial() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStore_NilRecordsNothing(t *testing.T) {
+	t.Parallel()
+
+	var s *Store
+	if err := s.Record(Position{MediaID: 1, Episode: 1}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if _, ok := s.Lookup(1, 1); ok {
+		t.Fatal("nil store returned a position")
+	}
+}
+
+func TestSessionLog_AddAndReload(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	l, err := OpenSessions(path)
+	if err != nil {
+		t.Fatalf("OpenSessions: %v", err)
+	}
+	now := time.Now()
+	if err := l.Add(Session{MediaID: 1, Episode: 2, Start: now, Watched: 20 * time.Minute, Streamed: true, Downloaded: 300}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := l.Add(Session{MediaID: 1, Episode: 1, Start: now.Add(-time.Hour), Watched: 4 * time.Minute}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	reloaded, err := OpenSessions(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	sessions := reloaded.Sessions()
+	if len(sessions) != 2 || sessions[0].Episode != 1 {
+		t.Fatalf("Sessions = %+v, want 2 oldest first", sessions)
+	}
+	watched, downloaded := Totals(sessions)
+	if watched != 24*time.Minute || downloaded != 300 {
+		t.Fatalf("Totals = %v, %d; want 24m, 300", watched, downloaded)
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday 2024-05-15; the week started on Monday 2024-05-13.
+	now := time.Date(2024, 5, 15, 18, 0, 0, 0, time.UTC)
+	sessions := []Session{
+		{Start: time.Date(2024, 5, 13, 0, 30, 0, 0, time.UTC), Watched: time.Hour},
+		{Start: time.Date(2024, 5, 12, 23, 0, 0, 0, time.UTC), Watched: 30 * time.Minute}, // Sunday, last week
+		{Start: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Watched: 2 * time.Hour},     // two weeks ago
+		{Start: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), Watched: 5 * time.Hour},     // out of range
+		{Start: time.Date(2024, 5, 21, 12, 0, 0, 0, time.UTC), Watched: 3 * time.Hour},    // next week: clock skew
+	}
+	got := Weekly(sessions, now, 3)
+	want := []time.Duration{2 * time.Hour, 30 * time.Minute, time.Hour}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Weekly = %v, want %v", got, want)
+		}
+	}
+}
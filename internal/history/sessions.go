@@ -0,0 +1,126 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rayanxn/ani-tui/internal/config"
+	"github.com/rayanxn/ani-tui/internal/fsutil"
+)
+
+// Session is one run of the player.
+type Session struct {
+	MediaID    int           `json:"media_id"`
+	Episode    int           `json:"episode"`
+	Start      time.Time     `json:"start"`
+	Watched    time.Duration `json:"watched"`    // time spent playing, excluding pauses and seeks
+	Streamed   bool          `json:"streamed"`   // false for local files
+	Downloaded int64         `json:"downloaded"` // bytes fetched from peers while streaming
+}
+
+// SessionLog is a persistent, concurrency-safe record of watch sessions.
+// A nil *SessionLog records nothing.
+type SessionLog struct {
+	mu       sync.Mutex
+	path     string
+	sessions []Session
+}
+
+// DefaultSessionsPath returns the session log location inside the app data
+// directory.
+func DefaultSessionsPath() (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions.json"), nil
+}
+
+// OpenSessions loads the session log stored at path. A missing file yields
+// an empty log.
+func OpenSessions(path string) (*SessionLog, error) {
+	l := &SessionLog{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return l, nil
+		}
+		return nil, fmt.Errorf("read sessions: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &l.sessions); err != nil {
+		return nil, fmt.Errorf("parse sessions: %w", err)
+	}
+	return l, nil
+}
+
+// Add appends s to the log.
+func (l *SessionLog) Add(s Session) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sessions = append(l.sessions, s)
+	return l.saveLocked()
+}
+
+// Sessions returns a copy of all sessions, oldest first.
+func (l *SessionLog) Sessions() []Session {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := slices.Clone(l.sessions)
+	slices.SortStableFunc(out, func(a, b Session) int { return a.Start.Compare(b.Start) })
+	return out
+}
+
+// Totals sums sessions' watch time and streamed bytes.
+func Totals(sessions []Session) (watched time.Duration, downloaded int64) {
+	for _, s := range sessions {
+		watched += s.Watched
+		downloaded += s.Downloaded
+	}
+	return watched, downloaded
+}
+
+// Weekly sums watch time over the n weeks up to and including the one
+// containing now, oldest first. Weeks start on Monday.
+func Weekly(sessions []Session, now time.Time, n int) []time.Duration {
+	out := make([]time.Duration, n)
+	current := weekStart(now)
+	for _, s := range sessions {
+		// Floor, not truncation, so later weeks come out negative; +12
+		// absorbs DST shifts.
+		ago := int(math.Floor((current.Sub(weekStart(s.Start)).Hours() + 12) / (7 * 24)))
+		if ago >= 0 && ago < n {
+			out[n-1-ago] += s.Watched
+		}
+	}
+	return out
+}
+
+// weekStart returns midnight on the Monday of t's week, in t's location.
+func weekStart(t time.Time) time.Time {
+	days := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.Date()
+	return time.Date(y, m, d-days, 0, 0, 0, 0, t.Location())
+}
+
+// saveLocked writes the log atomically. Callers must hold l.mu.
+func (l *SessionLog) saveLocked() error {
+	if err := fsutil.WriteJSONAtomic(l.path, l.sessions); err != nil {
+		return fmt.Errorf("save sessions: %w", err)
+	}
+	return nil
+}
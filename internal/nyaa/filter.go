@@ -85,11 +85,14 @@ func isDigitOnly(s string) bool {
 
 // scoreTitleMatch scores how well a torrent title matches the given alt titles.
 // Returns a normalized score; higher is better.
-// Extra tokens in the core that don't appear in the alt title are penalized,
-// which naturally rejects season mismatches (e.g. "Oshi no Ko" vs "Oshi no Ko 2nd Season").
+// Season and part markers are compared separately from the rest of the
+// title: a release naming a different season than the alt title is rejected
+// (e.g. "Oshi no Ko" vs "Oshi no Ko 2nd Season"), while "S2" and
+// "2nd Season" count as the same. Other core tokens that don't appear in the
+// alt title are penalized.
 func scoreTitleMatch(torrentTitle string, altTitles []string) float64 {
 	groupTags, core, _ := parseTitleZones(torrentTitle)
-	coreTokens := tokenize(core)
+	coreTokens, coreSeason, corePart := parseSeason(tokenize(core))
 
 	coreSet := make(map[string]bool, len(coreTokens))
 	for _, t := range coreTokens {
@@ -107,10 +110,13 @@ func scoreTitleMatch(torrentTitle string, altTitles []string) float64 {
 	var bestCount int
 
 	for _, alt := range altTitles {
-		altTokens := tokenize(alt)
+		altTokens, altSeason, altPart := parseSeason(tokenize(alt))
 		if len(altTokens) == 0 {
 			continue
 		}
+		if !sameSeason(altSeason, altPart, coreSeason, corePart) {
+			continue
+		}
 
 		var raw float64
 		for _, at := range altTokens {
@@ -125,7 +131,6 @@ func scoreTitleMatch(torrentTitle string, altTitles []string) float64 {
 
 		// Extra-token penalty: for multi-token alt titles, penalize core tokens
 		// not present in the alt set and alt tokens not present in the core.
-		// This rejects season mismatches without explicit season detection.
 		// The len >= 2 guard protects single-token titles like "86" or "K".
 		if len(altTokens) >= 2 {
 			altSet := make(map[string]bool, len(altTokens))
@@ -157,6 +162,12 @@ func scoreTitleMatch(torrentTitle string, altTitles []string) float64 {
 	return bestScore
 }
 
+// sameSeason reports whether an alt title and a release core name the same
+// season and part. A missing marker counts as the first.
+func sameSeason(altSeason, altPart, coreSeason, corePart int) bool {
+	return max(altSeason, 1) == max(coreSeason, 1) && max(altPart, 1) == max(corePart, 1)
+}
+
 const matchThreshold = 1.5
 
 // FilterByTitle filters torrent items by relevance to the given alternative titles.
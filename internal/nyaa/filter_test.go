@@ -227,6 +227,37 @@ func TestFilterByTitle(t *testing.T) {
 			},
 			wantTitles: []string{"[SubsPlease] Oshi no Ko 2nd Season - 01 (1080p) [hash]"},
 		},
+		{
+			name:      "season marker spellings are equivalent",
+			altTitles: []string{"Oshi no Ko 2nd Season"},
+			items: []Item{
+				{Title: "[Erai-raws] Oshi no Ko S2 - 01 [1080p]", Seeders: 90},
+				{Title: "[Group] Oshi no Ko Season 2 - 01 [720p]", Seeders: 80},
+				{Title: "[Group] Oshi no Ko S3 - 01 [720p]", Seeders: 70},
+			},
+			wantTitles: []string{
+				"[Erai-raws] Oshi no Ko S2 - 01 [1080p]",
+				"[Group] Oshi no Ko Season 2 - 01 [720p]",
+			},
+		},
+		{
+			name:      "synonyms match abbreviated releases",
+			altTitles: []string{"Attack on Titan Final Season", "Shingeki no Kyojin: The Final Season", "Shingeki no Kyojin S4"},
+			items: []Item{
+				{Title: "[Group] Shingeki no Kyojin S4 - 05 (1080p)", Seeders: 60},
+				{Title: "[Group] Shingeki no Kyojin S3 - 05 (1080p)", Seeders: 90},
+			},
+			wantTitles: []string{"[Group] Shingeki no Kyojin S4 - 05 (1080p)"},
+		},
+		{
+			name:      "part markers must agree",
+			altTitles: []string{"Mushoku Tensei Season 2 Part 2"},
+			items: []Item{
+				{Title: "[Group] Mushoku Tensei S2 Part 2 - 13 (1080p)", Seeders: 40},
+				{Title: "[Group] Mushoku Tensei S2 - 01 (1080p)", Seeders: 80},
+			},
+			wantTitles: []string{"[Group] Mushoku Tensei S2 Part 2 - 13 (1080p)"},
+		},
 	}
 
 	for _, tt := range tests {
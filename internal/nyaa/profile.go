@@ -0,0 +1,121 @@
+package nyaa
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Profile ranks releases by user preference. The zero Profile rejects nothing
+// and ranks by seeders alone.
+type Profile struct {
+	PreferredGroups []string // most preferred first
+	BlockedGroups   []string
+	Resolutions     []string // most preferred first, e.g. "1080p", "720p"
+	Codecs          []string // most preferred first: "HEVC", "AVC", "AV1"
+	MinSeeders      int
+	TrustedOnly     bool
+	SkipRemakes     bool
+}
+
+// Score weights. Group preference outweighs resolution, which outweighs
+// codec; seeders break ties and favour healthy swarms.
+const (
+	groupWeight      = 40
+	resolutionWeight = 30
+	codecWeight      = 10
+	maxSeederScore   = 10
+)
+
+// Score rates an item under the profile; higher is better. ok is false when
+// the profile rejects the item outright.
+func (p Profile) Score(it Item) (score float64, ok bool) {
+	if it.Seeders < p.MinSeeders {
+		return 0, false
+	}
+	if p.TrustedOnly && !it.IsTrusted() {
+		return 0, false
+	}
+	if p.SkipRemakes && it.IsRemake() {
+		return 0, false
+	}
+
+	rel := ParseRelease(it.Title)
+	if indexFold(p.BlockedGroups, rel.Group) >= 0 {
+		return 0, false
+	}
+
+	score += rankScore(p.PreferredGroups, rel.Group, groupWeight)
+	score += rankScore(p.Resolutions, rel.Resolution, resolutionWeight)
+	score += rankScore(p.Codecs, rel.Codec, codecWeight)
+	score += math.Min(maxSeederScore, math.Log2(float64(it.Seeders)+1))
+	return score, true
+}
+
+// Rank drops items the profile rejects and sorts the rest by score, then
+// seeders. The input slice is not modified.
+func (p Profile) Rank(items []Item) []Item {
+	type scored struct {
+		item  Item
+		score float64
+	}
+
+	kept := make([]scored, 0, len(items))
+	for _, it := range items {
+		if s, ok := p.Score(it); ok {
+			kept = append(kept, scored{item: it, score: s})
+		}
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].score != kept[j].score {
+			return kept[i].score > kept[j].score
+		}
+		return kept[i].item.Seeders > kept[j].item.Seeders
+	})
+
+	out := make([]Item, len(kept))
+	for i, k := range kept {
+		out[i] = k.item
+	}
+	return out
+}
+
+// Best returns the highest ranked single-episode release numbered any of
+// episodes. An episode of 0 accepts any release.
+func (p Profile) Best(items []Item, episodes ...int) (Item, bool) {
+	for _, it := range p.Rank(items) {
+		if it.InfoHash == "" {
+			continue
+		}
+		rel := ParseRelease(it.Title)
+		if slices.Contains(episodes, 0) || (!rel.Batch && slices.Contains(episodes, rel.Episode)) {
+			return it, true
+		}
+	}
+	return Item{}, false
+}
+
+// rankScore gives weight to the first entry of prefs, scaling down to
+// weight/len(prefs) for the last. Values not listed score 0.
+func rankScore(prefs []string, value string, weight float64) float64 {
+	i := indexFold(prefs, value)
+	if i < 0 {
+		return 0
+	}
+	return weight * float64(len(prefs)-i) / float64(len(prefs))
+}
+
+// indexFold returns the index of value in list, ignoring case, or -1.
+func indexFold(list []string, value string) int {
+	if value == "" {
+		return -1
+	}
+	for i, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,83 @@
+package nyaa
+
+import "testing"
+
+func TestProfileRank(t *testing.T) {
+	t.Parallel()
+
+	items := []Item{
+		{Title: "[Erai-raws] Show - 05 [1080p][HEVC]", InfoHash: "erai", Seeders: 900},
+		{Title: "[SubsPlease] Show - 05 (720p) [hash].mkv", InfoHash: "sp720", Seeders: 500},
+		{Title: "[SubsPlease] Show - 05 (1080p) [hash].mkv", InfoHash: "sp1080", Seeders: 300},
+		{Title: "[BadGroup] Show - 05 [1080p]", InfoHash: "bad", Seeders: 5000},
+		{Title: "[Judas] Show - 05 [1080p][HEVC]", InfoHash: "remake", Seeders: 50, Remake: "Yes"},
+		{Title: "[Anon] Show - 05 [1080p]", InfoHash: "dead", Seeders: 1},
+	}
+
+	p := Profile{
+		PreferredGroups: []string{"subsplease", "Erai-raws"},
+		BlockedGroups:   []string{"BadGroup"},
+		Resolutions:     []string{"1080p", "720p"},
+		Codecs:          []string{"HEVC"},
+		MinSeeders:      2,
+		SkipRemakes:     true,
+	}
+
+	got := p.Rank(items)
+	want := []string{"sp1080", "erai", "sp720"}
+	if len(got) != len(want) {
+		t.Fatalf("Rank returned %d items, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].InfoHash != w {
+			t.Errorf("Rank[%d] = %q, want %q", i, got[i].InfoHash, w)
+		}
+	}
+}
+
+func TestProfileZeroRanksBySeeders(t *testing.T) {
+	t.Parallel()
+
+	items := []Item{
+		{Title: "[A] Show - 01 [720p]", InfoHash: "a", Seeders: 10},
+		{Title: "[B] Show - 01 [1080p]", InfoHash: "b", Seeders: 90},
+		{Title: "[C] Show - 01 [480p]", InfoHash: "c", Seeders: 0, Remake: "Yes"},
+	}
+
+	got := Profile{}.Rank(items)
+	if len(got) != 3 || got[0].InfoHash != "b" || got[1].InfoHash != "a" {
+		t.Fatalf("zero profile Rank = %+v, want b, a, c", got)
+	}
+}
+
+func TestProfileTrustedOnly(t *testing.T) {
+	t.Parallel()
+
+	items := []Item{
+		{Title: "[A] Show - 01 [1080p]", InfoHash: "a", Seeders: 100},
+		{Title: "[B] Show - 01 [1080p]", InfoHash: "b", Seeders: 10, Trusted: "Yes"},
+	}
+	got := Profile{TrustedOnly: true}.Rank(items)
+	if len(got) != 1 || got[0].InfoHash != "b" {
+		t.Fatalf("TrustedOnly Rank = %+v, want only b", got)
+	}
+}
+
+func TestProfileBest(t *testing.T) {
+	t.Parallel()
+
+	items := []Item{
+		{Title: "[SubsPlease] Show (01-12) (1080p) [Batch]", InfoHash: "batch", Seeders: 2000},
+		{Title: "[SubsPlease] Show - 04 (1080p) [hash]", InfoHash: "ep4", Seeders: 1000},
+		{Title: "[Erai-raws] Show - 05 [720p]", InfoHash: "ep5", Seeders: 100},
+	}
+	p := Profile{Resolutions: []string{"1080p"}}
+
+	got, ok := p.Best(items, 5)
+	if !ok || got.InfoHash != "ep5" {
+		t.Fatalf("Best(5) = %q, %v; want ep5, true", got.InfoHash, ok)
+	}
+	if _, ok := p.Best(items, 6); ok {
+		t.Fatal("Best(6) found a release, want none")
+	}
+}
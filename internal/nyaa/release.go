@@ -0,0 +1,162 @@
+package nyaa
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Release holds metadata parsed from a release title or file name.
+type Release struct {
+	Group      string // leading [Group] tag, original case
+	Title      string // core show title without episode markers
+	Episode    int    // first episode number, 0 if unknown
+	EpisodeEnd int    // last episode of a range, 0 unless Batch
+	Season     int    // season number from an SxxEyy marker, 0 if absent
+	Resolution string // normalized, e.g. "1080p"
+	Codec      string // "HEVC", "AVC" or "AV1"
+	Batch      bool   // multi-episode release
+}
+
+var (
+	reSeasonEpisode = regexp.MustCompile(`(?i)\bS(\d{1,2})E(\d{1,4})(?:v\d)?\b`)
+	reEpisodeRange  = regexp.MustCompile(`(?:^|[\s(\[])(\d{1,4})\s*[-~]\s*(\d{1,4})(?:$|[\s)\]])`)
+	reDashEpisode   = regexp.MustCompile(`\s-\s(\d{1,4})(?:v\d)?(?:$|\s)`)
+	reEpisodeWord   = regexp.MustCompile(`(?i)\b(?:ep|episode|e)\s?(\d{1,4})(?:v\d)?\b`)
+	reTrailingNum   = regexp.MustCompile(`\s(\d{1,4})(?:v\d)?$`)
+	reResolution    = regexp.MustCompile(`(?i)\b(?:\d{3,4}x(480|540|576|720|1080|2160)|(480|540|576|720|1080|2160)[pi])\b`)
+	reFourK         = regexp.MustCompile(`(?i)\b4k\b`)
+	reHEVC          = regexp.MustCompile(`(?i)\b(?:x\.?265|h\.?265|hevc)\b`)
+	reAVC           = regexp.MustCompile(`(?i)\b(?:x\.?264|h\.?264|avc)\b`)
+	reAV1           = regexp.MustCompile(`(?i)\bav1\b`)
+	reBatchWord     = regexp.MustCompile(`(?i)\b(?:batch|complete)\b`)
+)
+
+// videoExtensions lists file extensions stripped before parsing file names.
+var videoExtensions = map[string]bool{
+	".mkv": true, ".mp4": true, ".avi": true, ".webm": true, ".m4v": true, ".ts": true,
+}
+
+// ParseRelease extracts release group, episode, resolution and codec from a
+// nyaa release title or a video file name.
+func ParseRelease(title string) Release {
+	raw := strings.TrimSpace(title)
+	if ext := strings.ToLower(path.Ext(raw)); videoExtensions[ext] {
+		raw = strings.TrimSuffix(raw, raw[len(raw)-len(ext):])
+	}
+	raw = strings.ReplaceAll(raw, "_", " ")
+
+	var rel Release
+
+	// Group tag keeps its original case for display and matching.
+	if strings.HasPrefix(raw, "[") {
+		if end := strings.Index(raw, "]"); end > 0 {
+			rel.Group = strings.TrimSpace(raw[1:end])
+		}
+	}
+
+	_, core, tech := parseTitleZones(raw)
+
+	switch {
+	case reResolution.MatchString(raw):
+		m := reResolution.FindStringSubmatch(raw)
+		rel.Resolution = m[1] + m[2] + "p"
+	case reFourK.MatchString(raw):
+		rel.Resolution = "2160p"
+	}
+
+	switch {
+	case reHEVC.MatchString(raw):
+		rel.Codec = "HEVC"
+	case reAV1.MatchString(raw):
+		rel.Codec = "AV1"
+	case reAVC.MatchString(raw):
+		rel.Codec = "AVC"
+	}
+
+	rel.Title = core
+	if m := reSeasonEpisode.FindStringSubmatchIndex(core); m != nil {
+		rel.Season, _ = strconv.Atoi(core[m[2]:m[3]])
+		rel.Episode, _ = strconv.Atoi(core[m[4]:m[5]])
+		rel.Title = core[:m[0]]
+	} else if m := lastSubmatchIndex(reEpisodeRange, core); m != nil {
+		rel.Episode, _ = strconv.Atoi(core[m[2]:m[3]])
+		rel.EpisodeEnd, _ = strconv.Atoi(core[m[4]:m[5]])
+		rel.Batch = rel.EpisodeEnd > rel.Episode
+		rel.Title = core[:m[0]]
+	} else if m := lastSubmatchIndex(reDashEpisode, core); m != nil {
+		rel.Episode, _ = strconv.Atoi(core[m[2]:m[3]])
+		rel.Title = core[:m[0]]
+	} else if m := lastSubmatchIndex(reEpisodeWord, core); m != nil {
+		rel.Episode, _ = strconv.Atoi(core[m[2]:m[3]])
+		rel.Title = core[:m[0]]
+	} else if m := reTrailingNum.FindStringSubmatchIndex(core); m != nil && !isResolution(core[m[2]:m[3]]) {
+		rel.Episode, _ = strconv.Atoi(core[m[2]:m[3]])
+		rel.Title = core[:m[0]]
+	}
+	rel.Title = strings.TrimRight(strings.TrimSpace(rel.Title), " -._")
+
+	if !rel.Batch && (reBatchWord.MatchString(core) || reBatchWord.MatchString(tech)) {
+		rel.Batch = true
+	}
+	if !rel.Batch && rel.Episode == 0 {
+		if m := reEpisodeRange.FindStringSubmatch(tech); m != nil {
+			rel.Episode, _ = strconv.Atoi(m[1])
+			rel.EpisodeEnd, _ = strconv.Atoi(m[2])
+			rel.Batch = rel.EpisodeEnd > rel.Episode
+		}
+	}
+
+	return rel
+}
+
+// PickRelease returns the single-episode release numbered any of episodes
+// (e.g. per-season and absolute) that best matches like: same group and
+// resolution first, then same group, then same resolution. Ties go to the
+// item with more seeders. Reports false when no item shares the group or
+// resolution.
+func PickRelease(items []Item, like Release, episodes ...int) (Item, bool) {
+	var best Item
+	bestScore := 0
+	for _, it := range items {
+		if it.InfoHash == "" {
+			continue
+		}
+		rel := ParseRelease(it.Title)
+		if rel.Batch || !slices.Contains(episodes, rel.Episode) {
+			continue
+		}
+
+		score := 0
+		if like.Group != "" && strings.EqualFold(rel.Group, like.Group) {
+			score += 2
+		}
+		if like.Resolution != "" && rel.Resolution == like.Resolution {
+			score++
+		}
+		if score > bestScore || (score == bestScore && score > 0 && it.Seeders > best.Seeders) {
+			best, bestScore = it, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// lastSubmatchIndex returns the submatch indices of the last match of re in s.
+func lastSubmatchIndex(re *regexp.Regexp, s string) []int {
+	all := re.FindAllStringSubmatchIndex(s, -1)
+	if len(all) == 0 {
+		return nil
+	}
+	return all[len(all)-1]
+}
+
+// isResolution reports whether a bare number is a common vertical resolution.
+func isResolution(s string) bool {
+	switch s {
+	case "480", "540", "576", "720", "1080", "2160":
+		return true
+	}
+	return false
+}
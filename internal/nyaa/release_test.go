@@ -0,0 +1,97 @@
+package nyaa
+
+import "testing"
+
+func TestParseRelease(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  string
+		want Release
+	}{
+		{
+			name: "subsplease episode",
+			raw:  "[SubsPlease] Takt Op. Destiny - 01 (1080p) [ABC123].mkv",
+			want: Release{Group: "SubsPlease", Title: "Takt Op. Destiny", Episode: 1, Resolution: "1080p"},
+		},
+		{
+			name: "batch range with codec",
+			raw:  "[Exiled-Destiny] Persona 4 The Animation 01-26 (Dual Audio) [BD 720p 8bit x265]",
+			want: Release{Group: "Exiled-Destiny", Title: "Persona 4 The Animation", Episode: 1, EpisodeEnd: 26, Resolution: "720p", Codec: "HEVC", Batch: true},
+		},
+		{
+			name: "season episode marker",
+			raw:  "Show.Name.S02E05.1080p.WEB.H.264",
+			want: Release{Title: "Show.Name", Season: 2, Episode: 5, Resolution: "1080p", Codec: "AVC"},
+		},
+		{
+			name: "numeric title keeps last dash episode",
+			raw:  "[SubsPlease] 86 - Eighty Six - 03 (1080p) [hash]",
+			want: Release{Group: "SubsPlease", Title: "86 - Eighty Six", Episode: 3, Resolution: "1080p"},
+		},
+		{
+			name: "episode with version suffix",
+			raw:  "[Erai-raws] ONE PIECE - 1080v2 [720p][HEVC]",
+			want: Release{Group: "Erai-raws", Title: "ONE PIECE", Episode: 1080, Resolution: "720p", Codec: "HEVC"},
+		},
+		{
+			name: "bare episode number is not a resolution",
+			raw:  "[SubsPlease] One Piece - 1080 (1920x1080) [hash]",
+			want: Release{Group: "SubsPlease", Title: "One Piece", Episode: 1080, Resolution: "1080p"},
+		},
+		{
+			name: "batch keyword",
+			raw:  "[Judas] Oshi no Ko (Season 1) [BD 2160p 4K][Batch]",
+			want: Release{Group: "Judas", Title: "Oshi no Ko", Resolution: "2160p", Batch: true},
+		},
+		{
+			name: "underscored file name",
+			raw:  "[Group]_My_Show_-_07_[480p].mp4",
+			want: Release{Group: "Group", Title: "My Show", Episode: 7, Resolution: "480p"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ParseRelease(tt.raw); got != tt.want {
+				t.Errorf("ParseRelease(%q)\n got %+v\nwant %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickRelease(t *testing.T) {
+	t.Parallel()
+
+	like := ParseRelease("[SubsPlease] Frieren - 04 (1080p) [AAAA].mkv")
+	items := []Item{
+		{Title: "[Erai-raws] Frieren - 05 [1080p][HEVC]", InfoHash: "a", Seeders: 900},
+		{Title: "[SubsPlease] Frieren - 05 (720p) [BBBB].mkv", InfoHash: "b", Seeders: 500},
+		{Title: "[SubsPlease] Frieren - 05 (1080p) [CCCC].mkv", InfoHash: "c", Seeders: 300},
+		{Title: "[SubsPlease] Frieren - 06 (1080p) [DDDD].mkv", InfoHash: "d", Seeders: 1000},
+		{Title: "[SubsPlease] Frieren (01-12) (1080p) [Batch]", InfoHash: "e", Seeders: 2000},
+	}
+
+	got, ok := PickRelease(items, like, 5)
+	if !ok || got.InfoHash != "c" {
+		t.Fatalf("PickRelease = %q, %v; want c, true", got.InfoHash, ok)
+	}
+
+	got, ok = PickRelease(items[:2], like, 5)
+	if !ok || got.InfoHash != "b" {
+		t.Fatalf("PickRelease without exact match = %q, %v; want b, true", got.InfoHash, ok)
+	}
+
+	// Either numbering of the episode is accepted.
+	got, ok = PickRelease(items[1:4], like, 1, 6)
+	if !ok || got.InfoHash != "d" {
+		t.Fatalf("PickRelease with absolute number = %q, %v; want d, true", got.InfoHash, ok)
+	}
+
+	other := ParseRelease("[Judas] Frieren - 04 [480p]")
+	if got, ok := PickRelease(items, other, 5); ok {
+		t.Fatalf("PickRelease with no shared group or resolution = %q, want no match", got.InfoHash)
+	}
+}
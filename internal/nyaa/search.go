@@ -2,31 +2,153 @@ package nyaa
 
 import (
 	"context"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/rayanxn/ani-tui/internal/cache"
 )
 
 const defaultFeedURL = "https://nyaa.si/"
 
+// PageSize is the most items nyaa.si returns per RSS page. A full page means
+// more results may follow.
+const PageSize = 75
+
+// Category selects a nyaa.si category (the c= parameter).
+type Category string
+
+const (
+	CategoryAllAnime   Category = "1_0"
+	CategoryEnglish    Category = "1_2" // Anime > English-translated, the default
+	CategoryNonEnglish Category = "1_3"
+	CategoryRaw        Category = "1_4"
+)
+
+// Categories lists the anime categories in the order the UI cycles them.
+var Categories = []Category{CategoryEnglish, CategoryNonEnglish, CategoryRaw, CategoryAllAnime}
+
+// Label returns a short name for display.
+func (c Category) Label() string {
+	switch c {
+	case CategoryAllAnime:
+		return "all anime"
+	case CategoryNonEnglish:
+		return "non-English"
+	case CategoryRaw:
+		return "raw"
+	default:
+		return "English"
+	}
+}
+
+// Filter selects nyaa.si's torrent filter (the f= parameter).
+type Filter string
+
 const (
-	// categoryAnimeEnglish filters results to Anime > English-translated (c=1_2).
-	categoryAnimeEnglish = "1_2"
-	// filterNoFilter applies no torrent filter (f=0: show all results).
-	filterNoFilter = "0"
+	FilterNone        Filter = "0" // show all results, the default
+	FilterNoRemakes   Filter = "1"
+	FilterTrustedOnly Filter = "2"
 )
 
+// Filters lists the torrent filters in the order the UI cycles them.
+var Filters = []Filter{FilterNone, FilterNoRemakes, FilterTrustedOnly}
+
+// Label returns a short name for display.
+func (f Filter) Label() string {
+	switch f {
+	case FilterNoRemakes:
+		return "no remakes"
+	case FilterTrustedOnly:
+		return "trusted only"
+	default:
+		return "no filter"
+	}
+}
+
+// SearchOptions tunes a nyaa.si query. The zero value searches
+// English-translated anime with no filter.
+type SearchOptions struct {
+	Category Category
+	Filter   Filter
+	// Sort asks nyaa.si to order results before the feed is truncated, so
+	// e.g. SortDate returns the newest uploads. Results are re-sorted
+	// locally by the same key; SortScore sorts locally by seeders.
+	Sort SortKey
+	// Page is the 1-based results page; 0 means the first.
+	Page int
+	// Refresh skips cached results and asks nyaa.si again.
+	Refresh bool
+}
+
+// withDefaults fills in empty fields.
+func (o SearchOptions) withDefaults() SearchOptions {
+	if o.Category == "" {
+		o.Category = CategoryEnglish
+	}
+	if o.Filter == "" {
+		o.Filter = FilterNone
+	}
+	return o
+}
+
+// Cycle returns the element after cur in list, wrapping around. A cur not in
+// list yields the first element.
+func Cycle[T comparable](list []T, cur T) T {
+	for i, v := range list {
+		if v == cur {
+			return list[(i+1)%len(list)]
+		}
+	}
+	return list[0]
+}
+
+// Next returns the category after c in Categories.
+func (c Category) Next() Category { return Cycle(Categories, c) }
+
+// Next returns the filter after f in Filters.
+func (f Filter) Next() Filter { return Cycle(Filters, f) }
+
 // Client fetches and decodes nyaa RSS results.
 type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	// Cache, when set, serves repeated searches for up to searchTTL, and
+	// until searchStaleTTL while a fresh copy is fetched in the background.
+	Cache *cache.Cache
+
+	fresh, stale time.Duration // searchTTL and searchStaleTTL; tests shorten them
+
+	mu           sync.Mutex
+	revalidating map[string]bool // feed URLs being refreshed in the background
+	wg           sync.WaitGroup  // background refreshes
 }
 
+// searchTTL is how long a cached search result is served without asking
+// nyaa.si again.
+const searchTTL = 15 * time.Minute
+
+// searchStaleTTL is how long a cached search result is still served once it
+// is past searchTTL, while it is refreshed in the background.
+const searchStaleTTL = 24 * time.Hour
+
+// revalidateTimeout bounds a background refresh, which has no caller to
+// cancel it.
+const revalidateTimeout = 30 * time.Second
+
+// cacheNamespace groups nyaa responses in the cache.
+const cacheNamespace = "nyaa-search"
+
 // NewClient returns a Client with sane defaults.
 func NewClient(httpClient *http.Client) *Client {
 	if httpClient == nil {
@@ -35,18 +157,26 @@ func NewClient(httpClient *http.Client) *Client {
 	return &Client{
 		BaseURL:    defaultFeedURL,
 		HTTPClient: httpClient,
+		fresh:      searchTTL,
+		stale:      searchStaleTTL,
 	}
 }
 
 var defaultClient = NewClient(nil)
 
-// Search fetches nyaa.si RSS results for a query and sorts by seeders desc.
-func Search(ctx context.Context, query string) ([]Item, error) {
-	return defaultClient.Search(ctx, query)
+// SetCache makes the package-level search functions cache their results.
+func SetCache(c *cache.Cache) {
+	defaultClient.Cache = c
+}
+
+// Search uses the default client.
+func Search(ctx context.Context, query string, opts SearchOptions) ([]Item, error) {
+	return defaultClient.Search(ctx, query, opts)
 }
 
-// Search fetches nyaa.si RSS results for a query and sorts by seeders desc.
-func (c *Client) Search(ctx context.Context, query string) ([]Item, error) {
+// Search fetches nyaa.si RSS results for a query with the given options. Results
+// are sorted by seeders desc unless opts.Sort picks another order.
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) ([]Item, error) {
 	query = strings.TrimSpace(query)
 	if query == "" {
 		return nil, fmt.Errorf("empty nyaa query")
@@ -62,14 +192,95 @@ func (c *Client) Search(ctx context.Context, query string) ([]Item, error) {
 		return nil, fmt.Errorf("parse nyaa url: %w", err)
 	}
 
+	opts = opts.withDefaults()
 	q := u.Query()
 	q.Set("page", "rss")
 	q.Set("q", query)
-	q.Set("c", categoryAnimeEnglish)
-	q.Set("f", filterNoFilter)
+	q.Set("c", string(opts.Category))
+	q.Set("f", string(opts.Filter))
+	if opts.Sort != SortScore {
+		q.Set("s", string(opts.Sort))
+		q.Set("o", "desc")
+	}
+	if opts.Page > 1 {
+		q.Set("p", strconv.Itoa(opts.Page))
+	}
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	items, err := c.fetch(ctx, u.String(), opts.Refresh)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Sort != SortScore {
+		return SortItems(items, opts.Sort), nil
+	}
+	return sortBySeeders(items), nil
+}
+
+// fetch returns the items of the RSS feed at feedURL. Unless refresh is set,
+// a cached copy younger than c.stale is returned, and one past c.fresh is
+// refreshed in the background for the next search.
+func (c *Client) fetch(ctx context.Context, feedURL string, refresh bool) ([]Item, error) {
+	if items, age, ok := c.cached(feedURL); ok && !refresh && age < c.stale {
+		if age >= c.fresh {
+			c.revalidate(feedURL)
+		}
+		return items, nil
+	}
+
+	items, err := c.download(ctx, feedURL)
+	if err != nil {
+		// Unreachable: an old answer beats none.
+		if items, _, ok := c.cached(feedURL); ok && ctx.Err() == nil {
+			return items, nil
+		}
+		return nil, err
+	}
+	return items, nil
+}
+
+// cached returns the cached items of feedURL and how old they are.
+func (c *Client) cached(feedURL string) ([]Item, time.Duration, bool) {
+	data, age, ok := c.Cache.Get(cacheNamespace, feedURL)
+	if !ok {
+		return nil, 0, false
+	}
+	var items []Item
+	if json.Unmarshal(data, &items) != nil {
+		return nil, 0, false
+	}
+	return items, age, true
+}
+
+// revalidate refreshes the cached copy of feedURL in the background, unless
+// a refresh is already running.
+func (c *Client) revalidate(feedURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.revalidating[feedURL] {
+		return
+	}
+	if c.revalidating == nil {
+		c.revalidating = make(map[string]bool)
+	}
+	c.revalidating[feedURL] = true
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), revalidateTimeout)
+		defer cancel()
+		_, _ = c.download(ctx, feedURL) // a failed refresh keeps the old copy
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.revalidating, feedURL)
+	}()
+}
+
+// download fetches the RSS feed at feedURL and caches its items.
+func (c *Client) download(ctx context.Context, feedURL string) ([]Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -90,15 +301,10 @@ func (c *Client) Search(ctx context.Context, query string) ([]Item, error) {
 		return nil, fmt.Errorf("decode rss: %w", err)
 	}
 
-	items := rss.Channel.Items
-	sort.Slice(items, func(i, j int) bool {
-		if items[i].Seeders == items[j].Seeders {
-			return items[i].Downloads > items[j].Downloads
-		}
-		return items[i].Seeders > items[j].Seeders
-	})
-
-	return items, nil
+	if data, err := json.Marshal(rss.Channel.Items); err == nil {
+		_ = c.Cache.Put(cacheNamespace, feedURL, data) // best-effort
+	}
+	return rss.Channel.Items, nil
 }
 
 // SearchRequest bundles all parameters needed for a torrent search.
@@ -107,24 +313,116 @@ type SearchRequest struct {
 	AltTitles    []string
 	Episode      int
 	Quality      string
+	// EpisodeOffset is the number of episodes in earlier seasons. When set,
+	// releases of the first season's BaseTitles numbered Episode+EpisodeOffset
+	// are searched and accepted as well.
+	EpisodeOffset int
+	BaseTitles    []string
+	// Query replaces the generated query text. A custom query is searched
+	// as-is: no title filtering and no alt-title fallback.
+	Query   string
+	Options SearchOptions
+}
+
+// AbsoluteEpisode returns Episode in absolute numbering, or 0 when the
+// request has no offset.
+func (r SearchRequest) AbsoluteEpisode() int {
+	if r.EpisodeOffset <= 0 {
+		return 0
+	}
+	return r.Episode + r.EpisodeOffset
+}
+
+// Episodes returns the episode numbers a release may use for the requested
+// episode: the per-season number and, if known, the absolute one.
+func (r SearchRequest) Episodes() []int {
+	if abs := r.AbsoluteEpisode(); abs > 0 {
+		return []int{r.Episode, abs}
+	}
+	return []int{r.Episode}
+}
+
+// filter keeps the items whose titles match the request: AltTitles for
+// per-season releases, plus BaseTitles releases of the absolute episode.
+func (r SearchRequest) filter(items []Item) []Item {
+	kept := FilterByTitle(items, r.AltTitles)
+	abs := r.AbsoluteEpisode()
+	if abs == 0 || len(r.BaseTitles) == 0 {
+		return kept
+	}
+
+	seen := make(map[string]bool, len(kept))
+	for _, it := range kept {
+		seen[it.Title] = true
+	}
+	var absolute []Item
+	for _, it := range items {
+		if rel := ParseRelease(it.Title); !seen[it.Title] && !rel.Batch && rel.Episode == abs {
+			absolute = append(absolute, it)
+		}
+	}
+	return append(kept, FilterByTitle(absolute, r.BaseTitles)...)
+}
+
+// QueryText returns the query the request searches first.
+func (r SearchRequest) QueryText() string {
+	if q := strings.TrimSpace(r.Query); q != "" {
+		return q
+	}
+	return BuildSearchQuery(r.PrimaryTitle, r.Episode, r.Quality)
 }
 
+// minResults is how many filtered results SearchWithFallback aims for before
+// it stops trying other queries.
+const minResults = 3
+
+// maxAltSearches bounds how many alt-title queries run at once.
+const maxAltSearches = 3
+
 // SearchWithFallback searches the primary title first, filters results, and
-// lazily tries non-CJK alt titles if the filtered count is below minResults.
-// Results are deduplicated by InfoHash and re-sorted.
+// tries non-CJK alt titles concurrently if the filtered count is below
+// minResults. With an EpisodeOffset the first season's titles are also
+// searched with the absolute episode number. Results are deduplicated by
+// InfoHash and re-sorted.
 func (c *Client) SearchWithFallback(ctx context.Context, req SearchRequest) ([]Item, error) {
-	const minResults = 3
+	items, _, err := c.SearchWithFallbackFunc(ctx, req, nil)
+	return items, err
+}
+
+// Paging tells how far a search read its primary query: the last nyaa.si
+// page fetched and whether that page was full, so that more may follow.
+// Fullness is judged before title filtering, as in SearchPage.
+type Paging struct {
+	Page int
+	More bool
+}
 
-	primaryQuery := BuildSearchQuery(req.PrimaryTitle, req.Episode, req.Quality)
-	items, err := c.Search(ctx, primaryQuery)
+// SearchWithFallbackFunc is SearchWithFallback that also calls emit with the
+// filtered, sorted results so far each time a query adds to them, and
+// reports how far the primary query was paged. emit is called from one
+// goroutine at a time and must not block.
+func (c *Client) SearchWithFallbackFunc(ctx context.Context, req SearchRequest, emit func([]Item)) ([]Item, Paging, error) {
+	primaryQuery := req.QueryText()
+	items, err := c.Search(ctx, primaryQuery, req.Options)
 	if err != nil {
-		return nil, err
+		return nil, Paging{}, err
+	}
+	paging := Paging{Page: 1, More: len(items) >= PageSize}
+	if strings.TrimSpace(req.Query) != "" {
+		return items, paging, nil
 	}
 
-	filtered := FilterByTitle(items, req.AltTitles)
+	filtered := req.filter(items)
 	if len(filtered) >= minResults {
-		return filtered, nil
+		return filtered, paging, nil
+	}
+	// report hands emit its own copy, since items keeps growing.
+	report := func(filtered []Item) {
+		if emit != nil {
+			emit(sortBySeeders(append([]Item(nil), filtered...)))
+		}
 	}
+	report(filtered)
 
 	// Collect already-seen info hashes from primary results.
 	seen := make(map[string]bool)
@@ -134,45 +432,137 @@ func (c *Client) SearchWithFallback(ctx context.Context, req SearchRequest) ([]I
 		}
 	}
 
-	// Try each non-CJK alt title that produces a different query.
-	for _, alt := range req.AltTitles {
-		if IsLikelyCJK(alt) {
-			continue
-		}
-		q := BuildSearchQuery(alt, req.Episode, req.Quality)
-		if q == primaryQuery {
-			continue
+	// A full first page that filtered down to too little likely pushed the
+	// wanted releases onto page two.
+	if len(items) >= PageSize {
+		opts := req.Options
+		opts.Page = 2
+		if extra, err := c.Search(ctx, primaryQuery, opts); err == nil {
+			paging = Paging{Page: 2, More: len(extra) >= PageSize}
+			items = appendUnseen(items, extra, seen)
+			filtered = req.filter(items)
+			if len(filtered) >= minResults {
+				return sortBySeeders(filtered), paging, nil
+			}
+			report(filtered)
 		}
+	}
 
-		extra, searchErr := c.Search(ctx, q)
-		if searchErr != nil {
-			continue
+	// Try each non-CJK alt title that produces a different query, then the
+	// first season's titles with the absolute episode number, a few at a
+	// time, and stop the rest once enough results are in.
+	var queries []string
+	queried := map[string]bool{primaryQuery: true}
+	addQuery := func(title string, episode int) {
+		if IsLikelyCJK(title) {
+			return
 		}
-		for _, it := range extra {
-			if it.InfoHash != "" && seen[it.InfoHash] {
-				continue
-			}
-			if it.InfoHash != "" {
-				seen[it.InfoHash] = true
-			}
-			items = append(items, it)
+		q := BuildSearchQuery(title, episode, req.Quality)
+		if !queried[q] {
+			queried[q] = true
+			queries = append(queries, q)
 		}
+	}
+	for _, alt := range req.AltTitles {
+		addQuery(alt, req.Episode)
+	}
+	if abs := req.AbsoluteEpisode(); abs > 0 {
+		for _, base := range req.BaseTitles {
+			addQuery(base, abs)
+		}
+	}
 
-		filtered = FilterByTitle(items, req.AltTitles)
-		if len(filtered) >= minResults {
+	altCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g, gctx := errgroup.WithContext(altCtx)
+	g.SetLimit(maxAltSearches)
+
+	var mu sync.Mutex
+	for _, q := range queries {
+		if gctx.Err() != nil {
 			break
 		}
+
+		g.Go(func() error {
+			extra, err := c.Search(gctx, q, req.Options)
+			if err != nil {
+				// A failed alt query only means fewer results.
+				return nil
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if gctx.Err() != nil {
+				return nil
+			}
+			before := len(items)
+			items = appendUnseen(items, extra, seen)
+			if len(items) == before {
+				return nil
+			}
+			filtered := req.filter(items)
+			if len(filtered) >= minResults {
+				cancel()
+			}
+			report(filtered)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, Paging{}, err
 	}
 
 	// Re-filter and re-sort the merged set.
-	filtered = FilterByTitle(items, req.AltTitles)
-	sort.Slice(filtered, func(i, j int) bool {
-		if filtered[i].Seeders == filtered[j].Seeders {
-			return filtered[i].Downloads > filtered[j].Downloads
+	return sortBySeeders(req.filter(items)), paging, nil
+}
+
+// SearchPage fetches one page of the request's primary query, filtered by
+// title unless the query is custom. more reports whether nyaa.si may have
+// further pages.
+func (c *Client) SearchPage(ctx context.Context, req SearchRequest, page int) (items []Item, more bool, err error) {
+	opts := req.Options
+	opts.Page = page
+	raw, err := c.Search(ctx, req.QueryText(), opts)
+	if err != nil {
+		return nil, false, err
+	}
+	more = len(raw) >= PageSize
+	if strings.TrimSpace(req.Query) != "" {
+		return raw, more, nil
+	}
+	return req.filter(raw), more, nil
+}
+
+// SearchPage uses the default client.
+func SearchPage(ctx context.Context, req SearchRequest, page int) ([]Item, bool, error) {
+	return defaultClient.SearchPage(ctx, req, page)
+}
+
+// appendUnseen appends the items of extra whose info hash isn't in seen,
+// recording them as seen.
+func appendUnseen(items, extra []Item, seen map[string]bool) []Item {
+	for _, it := range extra {
+		if it.InfoHash != "" && seen[it.InfoHash] {
+			continue
+		}
+		if it.InfoHash != "" {
+			seen[it.InfoHash] = true
+		}
+		items = append(items, it)
+	}
+	return items
+}
+
+// sortBySeeders orders items by seeders, then downloads, descending.
+func sortBySeeders(items []Item) []Item {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Seeders == items[j].Seeders {
+			return items[i].Downloads > items[j].Downloads
 		}
-		return filtered[i].Seeders > filtered[j].Seeders
+		return items[i].Seeders > items[j].Seeders
 	})
-	return filtered, nil
+	return items
 }
 
 // SearchWithFallback uses the default client.
@@ -180,6 +570,11 @@ func SearchWithFallback(ctx context.Context, req SearchRequest) ([]Item, error)
 	return defaultClient.SearchWithFallback(ctx, req)
 }
 
+// SearchWithFallbackFunc uses the default client.
+func SearchWithFallbackFunc(ctx context.Context, req SearchRequest, emit func([]Item)) ([]Item, Paging, error) {
+	return defaultClient.SearchWithFallbackFunc(ctx, req, emit)
+}
+
 // IsLikelyCJK returns true if the string contains CJK (Han, Katakana, Hiragana) characters.
 // Used to skip native Japanese/Chinese titles from Nyaa search queries.
 func IsLikelyCJK(s string) bool {
@@ -2,9 +2,17 @@ package nyaa
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/rayanxn/ani-tui/internal/cache"
 )
 
 func TestClientSearch_DecodesAndSortsBySeedersThenDownloads(t *testing.T) {
@@ -58,7 +66,7 @@ func TestClientSearch_DecodesAndSortsBySeedersThenDownloads(t *testing.T) {
 	client := NewClient(srv.Client())
 	client.BaseURL = srv.URL
 
-	items, err := client.Search(context.Background(), "one piece")
+	items, err := client.Search(context.Background(), "one piece", SearchOptions{})
 	if err != nil {
 		t.Fatalf("Search returned error: %v", err)
 	}
@@ -75,6 +83,158 @@ func TestClientSearch_DecodesAndSortsBySeedersThenDownloads(t *testing.T) {
 	}
 }
 
+func TestClientSearch_AppliesOptions(t *testing.T) {
+	t.Parallel()
+
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:nyaa="https://nyaa.si/xmlns/nyaa">
+  <channel><title>Nyaa</title>
+    <item><title>Old</title><pubDate>Sun, 01 Oct 2023 10:00:00 -0000</pubDate><nyaa:infoHash>old</nyaa:infoHash><nyaa:seeders>90</nyaa:seeders></item>
+    <item><title>New</title><pubDate>Tue, 03 Oct 2023 10:00:00 -0000</pubDate><nyaa:infoHash>new</nyaa:infoHash><nyaa:seeders>1</nyaa:seeders></item>
+  </channel>
+</rss>`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+
+	items, err := client.Search(context.Background(), "frieren", SearchOptions{
+		Category: CategoryRaw,
+		Filter:   FilterTrustedOnly,
+		Sort:     SortDate,
+	})
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if got.Get("c") != "1_4" || got.Get("f") != "2" || got.Get("s") != "id" || got.Get("o") != "desc" {
+		t.Fatalf("unexpected query params: %v", got)
+	}
+	if len(items) != 2 || items[0].Title != "New" {
+		t.Fatalf("expected newest first, got %+v", items)
+	}
+}
+
+func TestSearchWithFallback_CustomQuerySkipsFiltering(t *testing.T) {
+	t.Parallel()
+
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:nyaa="https://nyaa.si/xmlns/nyaa">
+  <channel><title>Nyaa</title>
+    <item><title>[Sub] Something Else - 01</title><nyaa:infoHash>aaa</nyaa:infoHash><nyaa:seeders>5</nyaa:seeders></item>
+  </channel>
+</rss>`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+
+	items, err := client.SearchWithFallback(context.Background(), SearchRequest{
+		PrimaryTitle: "My Anime",
+		AltTitles:    []string{"My Anime", "Alt Name"},
+		Episode:      1,
+		Query:        "something else 01",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries) != 1 || queries[0] != "something else 01" {
+		t.Fatalf("expected only the custom query, got %q", queries)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected unfiltered result, got %d items", len(items))
+	}
+}
+
+func TestClientSearch_UsesCache(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(rssPage("x", []string{"[Sub] My Anime - 01"})))
+	}))
+	defer srv.Close()
+
+	store, err := cache.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("cache.Open: %v", err)
+	}
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+	client.Cache = store
+
+	for range 2 {
+		items, err := client.Search(context.Background(), "my anime", SearchOptions{})
+		if err != nil || len(items) != 1 {
+			t.Fatalf("Search = %d items, %v", len(items), err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected the repeat search to be cached, got %d requests", got)
+	}
+
+	if _, err := client.Search(context.Background(), "my anime", SearchOptions{Refresh: true}); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected Refresh to bypass the cache, got %d requests", got)
+	}
+}
+
+func TestClientSearch_RevalidatesStaleCache(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		titles := []string{"[Sub] My Anime - 01"}
+		if n > 1 {
+			titles = append(titles, "[Other] My Anime - 01")
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(rssPage("x", titles)))
+	}))
+	defer srv.Close()
+
+	store, err := cache.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("cache.Open: %v", err)
+	}
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+	client.Cache = store
+	client.fresh = 0 // every cached result is stale
+
+	if _, err := client.Search(context.Background(), "my anime", SearchOptions{}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	// The stale copy is served at once and refreshed in the background.
+	items, err := client.Search(context.Background(), "my anime", SearchOptions{})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("stale Search = %d items, %v; want the cached 1", len(items), err)
+	}
+	client.wg.Wait()
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected one background refresh, got %d requests", got)
+	}
+	client.fresh = time.Hour
+	items, _ = client.Search(context.Background(), "my anime", SearchOptions{})
+	if len(items) != 2 || calls.Load() != 2 {
+		t.Fatalf("next Search = %d items after %d requests; want the refreshed 2 from the cache", len(items), calls.Load())
+	}
+}
+
 func TestClientSearch_ErrorsOnNon200(t *testing.T) {
 	t.Parallel()
 
@@ -86,7 +246,7 @@ func TestClientSearch_ErrorsOnNon200(t *testing.T) {
 	client := NewClient(srv.Client())
 	client.BaseURL = srv.URL
 
-	_, err := client.Search(context.Background(), "naruto")
+	_, err := client.Search(context.Background(), "naruto", SearchOptions{})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -96,7 +256,7 @@ func TestClientSearch_ErrorsOnEmptyQuery(t *testing.T) {
 	t.Parallel()
 
 	client := NewClient(nil)
-	_, err := client.Search(context.Background(), "   ")
+	_, err := client.Search(context.Background(), "   ", SearchOptions{})
 	if err == nil {
 		t.Fatal("expected error for empty query")
 	}
@@ -185,6 +345,283 @@ func TestSearchWithFallback_FallsBackOnFewResults(t *testing.T) {
 	}
 }
 
+// rssPage renders an RSS feed with the given item titles, hashed by prefix.
+func rssPage(prefix string, titles []string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:nyaa="https://nyaa.si/xmlns/nyaa">
+  <channel><title>Nyaa</title>`)
+	for i, title := range titles {
+		fmt.Fprintf(&b, `<item><title>%s</title><nyaa:infoHash>%s%d</nyaa:infoHash><nyaa:seeders>%d</nyaa:seeders></item>`,
+			title, prefix, i, 100-i)
+	}
+	b.WriteString(`</channel></rss>`)
+	return b.String()
+}
+
+func TestSearchWithFallback_PullsSecondPage(t *testing.T) {
+	t.Parallel()
+
+	// A full first page of mostly unrelated releases.
+	first := []string{"[Sub] My Anime - 01"}
+	for len(first) < PageSize {
+		first = append(first, "[Sub] Other Show - 01")
+	}
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("p")
+		pages = append(pages, page)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		if page == "2" {
+			_, _ = w.Write([]byte(rssPage("p2-", []string{"[A] My Anime - 01", "[B] My Anime - 01"})))
+			return
+		}
+		_, _ = w.Write([]byte(rssPage("p1-", first)))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+
+	items, err := client.SearchWithFallback(context.Background(), SearchRequest{
+		PrimaryTitle: "My Anime",
+		AltTitles:    []string{"My Anime"},
+		Episode:      1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items across two pages, got %d: %+v", len(items), items)
+	}
+	if len(pages) != 2 || pages[0] != "" || pages[1] != "2" {
+		t.Fatalf("expected first page then p=2, got %q", pages)
+	}
+}
+
+func TestClientSearchPage(t *testing.T) {
+	t.Parallel()
+
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(rssPage("x", []string{"[Sub] My Anime - 01", "[Sub] Other Show - 01"})))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+
+	req := SearchRequest{PrimaryTitle: "My Anime", AltTitles: []string{"My Anime"}, Episode: 1}
+	items, more, err := client.SearchPage(context.Background(), req, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Get("p") != "3" {
+		t.Fatalf("expected p=3, got %v", got)
+	}
+	if more {
+		t.Fatal("a short page should report no more results")
+	}
+	if len(items) != 1 || items[0].Title != "[Sub] My Anime - 01" {
+		t.Fatalf("expected title-filtered results, got %+v", items)
+	}
+
+	req.Query = "anything"
+	items, _, err = client.SearchPage(context.Background(), req, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Has("p") {
+		t.Fatalf("first page should omit p, got %v", got)
+	}
+	if len(items) != 2 {
+		t.Fatalf("custom query should skip filtering, got %d items", len(items))
+	}
+}
+
+func TestSearchWithFallback_AltTitlesRunConcurrently(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, peak, calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if q := r.URL.Query().Get("q"); q != "My Anime 01" {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			inFlight.Add(-1)
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(rssPage("x", nil)))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+
+	_, err := client.SearchWithFallback(context.Background(), SearchRequest{
+		PrimaryTitle: "My Anime",
+		AltTitles:    []string{"My Anime", "Alt One", "Alt Two", "Alt Three", "Alt Four", "Alt Five"},
+		Episode:      1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := calls.Load(); got != 6 {
+		t.Fatalf("expected primary plus 5 alt queries, got %d", got)
+	}
+	if got := peak.Load(); got < 2 || got > maxAltSearches {
+		t.Fatalf("expected between 2 and %d concurrent alt queries, got %d", maxAltSearches, got)
+	}
+}
+
+func TestSearchWithFallbackFunc_ReportsRawPaging(t *testing.T) {
+	t.Parallel()
+
+	// Two full pages that each filter down to a single match.
+	full := []string{"[Sub] My Anime - 01"}
+	for len(full) < PageSize {
+		full = append(full, "[Sub] Other Show - 01")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(rssPage("p"+r.URL.Query().Get("p")+"-", full)))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+
+	items, paging, err := client.SearchWithFallbackFunc(context.Background(), SearchRequest{
+		PrimaryTitle: "My Anime",
+		AltTitles:    []string{"My Anime"},
+		Episode:      1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected one match per page, got %d", len(items))
+	}
+	if paging != (Paging{Page: 2, More: true}) {
+		t.Fatalf("paging = %+v, want page 2 with more to come", paging)
+	}
+}
+
+func TestSearchWithFallbackFunc_StreamsAndStopsOnceEnough(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		switch r.URL.Query().Get("q") {
+		case "My Anime 01":
+			_, _ = w.Write([]byte(rssPage("p", []string{"[A] My Anime - 01"})))
+		case "Fast Title 01":
+			time.Sleep(20 * time.Millisecond)
+			_, _ = w.Write([]byte(rssPage("f", []string{"[B] Fast Title - 01", "[C] Fast Title - 01"})))
+		default:
+			// Slow mirrors that should be cancelled.
+			select {
+			case <-time.After(5 * time.Second):
+			case <-r.Context().Done():
+				return
+			}
+			_, _ = w.Write([]byte(rssPage("s", []string{"[D] Slow Title - 01"})))
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+
+	var mu sync.Mutex
+	var updates [][]Item
+	start := time.Now()
+	items, _, err := client.SearchWithFallbackFunc(context.Background(), SearchRequest{
+		PrimaryTitle: "My Anime",
+		AltTitles:    []string{"My Anime", "Fast Title", "Slow Title", "Slower Title"},
+		Episode:      1,
+	}, func(items []Item) {
+		mu.Lock()
+		updates = append(updates, items)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("slow alt queries were not cancelled, took %v", elapsed)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d: %+v", len(items), items)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(updates) != 2 {
+		t.Fatalf("expected primary and fast-title updates, got %d", len(updates))
+	}
+	if len(updates[0]) != 1 || len(updates[1]) != 3 {
+		t.Fatalf("unexpected update sizes: %d then %d", len(updates[0]), len(updates[1]))
+	}
+}
+
+func TestSearchWithFallback_AcceptsAbsoluteNumbering(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		mu.Lock()
+		queries = append(queries, q)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/rss+xml")
+		switch q {
+		case "My Anime Season 2 01":
+			_, _ = w.Write([]byte(rssPage("s", []string{"[A] My Anime S2 - 01", "[B] My Anime - 01"})))
+		case "My Anime 26":
+			_, _ = w.Write([]byte(rssPage("a", []string{"[C] My Anime - 26", "[D] My Anime - 27"})))
+		default:
+			_, _ = w.Write([]byte(rssPage("x", nil)))
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.Client())
+	client.BaseURL = srv.URL
+
+	req := SearchRequest{
+		PrimaryTitle:  "My Anime Season 2",
+		AltTitles:     []string{"My Anime Season 2"},
+		Episode:       1,
+		EpisodeOffset: 25,
+		BaseTitles:    []string{"My Anime"},
+	}
+	if got := req.Episodes(); len(got) != 2 || got[0] != 1 || got[1] != 26 {
+		t.Fatalf("Episodes() = %v, want [1 26]", got)
+	}
+
+	items, err := client.SearchWithFallback(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, it := range items {
+		got[it.Title] = true
+	}
+	if len(got) != 2 || !got["[A] My Anime S2 - 01"] || !got["[C] My Anime - 26"] {
+		t.Fatalf("expected season and absolute releases, got %v (queries %q)", got, queries)
+	}
+}
+
 func TestSearchWithFallback_SkipsCJKAltTitles(t *testing.T) {
 	t.Parallel()
 
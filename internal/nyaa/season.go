@@ -0,0 +1,82 @@
+package nyaa
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ordinalWords maps spelled-out ordinals used in season names to numbers.
+var ordinalWords = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5,
+	"sixth": 6, "seventh": 7, "eighth": 8, "ninth": 9, "tenth": 10,
+}
+
+var (
+	seasonTokenRe = regexp.MustCompile(`^s(\d{1,2})(?:e\d+)?$`)
+	ordinalRe     = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)$`)
+)
+
+// parseSeason removes season and part markers ("S2", "S02E05", "Season 2",
+// "2nd Season", "Second Season", "Part 2", "Cour 2") from lowercase tokens.
+// It returns the remaining tokens and the numbers found, 0 where absent.
+func parseSeason(tokens []string) (base []string, season, part int) {
+	base = make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		next := ""
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		if m := seasonTokenRe.FindStringSubmatch(tok); m != nil {
+			season, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if (tok == "season" || tok == "part" || tok == "cour") && isDigitOnly(next) {
+			n, _ := strconv.Atoi(next)
+			if tok == "season" {
+				season = n
+			} else {
+				part = n
+			}
+			i++
+			continue
+		}
+		if next == "season" || next == "part" || next == "cour" {
+			n := ordinalWords[tok]
+			if m := ordinalRe.FindStringSubmatch(tok); m != nil {
+				n, _ = strconv.Atoi(m[1])
+			}
+			if n > 0 {
+				if next == "season" {
+					season = n
+				} else {
+					part = n
+				}
+				i++
+				continue
+			}
+		}
+		base = append(base, tok)
+	}
+	return base, season, part
+}
+
+// SeasonOf returns the season and part numbers named in a title, 0 where
+// the title has no marker.
+func SeasonOf(title string) (season, part int) {
+	_, season, part = parseSeason(tokenize(title))
+	return season, part
+}
+
+var seasonMarkerRe = regexp.MustCompile(`(?i)\b(?:s\d{1,2}|(?:season|part|cour)\s+\d{1,2}|(?:\d{1,2}(?:st|nd|rd|th)|first|second|third|fourth|fifth|sixth|seventh|eighth|ninth|tenth)\s+(?:season|part|cour))\b`)
+
+// BaseTitle strips season and part markers from a title, along with any
+// punctuation they leave dangling, e.g. "Oshi no Ko 2nd Season" becomes
+// "Oshi no Ko".
+func BaseTitle(title string) string {
+	s := seasonMarkerRe.ReplaceAllString(title, " ")
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.TrimRight(s, " :-–")
+}
@@ -0,0 +1,52 @@
+package nyaa
+
+import "testing"
+
+func TestSeasonOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		title      string
+		wantSeason int
+		wantPart   int
+	}{
+		{"Oshi no Ko", 0, 0},
+		{"Oshi no Ko 2nd Season", 2, 0},
+		{"Oshi no Ko S2", 2, 0},
+		{"Show S02E05", 2, 0},
+		{"Show Season 3", 3, 0},
+		{"Show Third Season", 3, 0},
+		{"Shingeki no Kyojin Season 3 Part 2", 3, 2},
+		{"Show: 2nd Part", 0, 2},
+		{"Show Cour 2", 0, 2},
+		{"86 Eighty Six", 0, 0},
+		{"Part-Time Hero", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+			season, part := SeasonOf(tt.title)
+			if season != tt.wantSeason || part != tt.wantPart {
+				t.Errorf("SeasonOf(%q) = %d, %d; want %d, %d", tt.title, season, part, tt.wantSeason, tt.wantPart)
+			}
+		})
+	}
+}
+
+func TestBaseTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"Oshi no Ko 2nd Season":                              "Oshi no Ko",
+		"Shingeki no Kyojin Season 3 Part 2":                 "Shingeki no Kyojin",
+		"Mushoku Tensei II: Isekai Ittara Honki Dasu Part 2": "Mushoku Tensei II: Isekai Ittara Honki Dasu",
+		"Kaguya-sama: Love is War - Second Season":           "Kaguya-sama: Love is War",
+		"Frieren": "Frieren",
+	}
+	for in, want := range tests {
+		if got := BaseTitle(in); got != want {
+			t.Errorf("BaseTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
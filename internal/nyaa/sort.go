@@ -0,0 +1,60 @@
+package nyaa
+
+import "sort"
+
+// SortKey orders search results. Values other than SortScore match nyaa.si's
+// own sort parameter.
+type SortKey string
+
+const (
+	SortScore     SortKey = ""          // relevance: ranking profile, then seeders
+	SortSeeders   SortKey = "seeders"   // most seeders first
+	SortSize      SortKey = "size"      // largest first
+	SortDate      SortKey = "id"        // newest first
+	SortDownloads SortKey = "downloads" // most downloads first
+)
+
+// SortKeys lists the sort orders in the order the UI cycles through them.
+var SortKeys = []SortKey{SortScore, SortSeeders, SortSize, SortDate, SortDownloads}
+
+// Label returns a short name for display.
+func (k SortKey) Label() string {
+	switch k {
+	case SortSeeders:
+		return "seeders"
+	case SortSize:
+		return "size"
+	case SortDate:
+		return "date"
+	case SortDownloads:
+		return "downloads"
+	default:
+		return "relevance"
+	}
+}
+
+// Next returns the sort key after k in SortKeys, wrapping around.
+func (k SortKey) Next() SortKey { return Cycle(SortKeys, k) }
+
+// SortItems returns a copy of items in descending order of key. SortScore
+// keeps the incoming order, which is already ranked by the profile.
+func SortItems(items []Item, key SortKey) []Item {
+	out := make([]Item, len(items))
+	copy(out, items)
+
+	var less func(a, b Item) bool
+	switch key {
+	case SortSeeders:
+		less = func(a, b Item) bool { return a.Seeders > b.Seeders }
+	case SortSize:
+		less = func(a, b Item) bool { return a.SizeBytes() > b.SizeBytes() }
+	case SortDate:
+		less = func(a, b Item) bool { return a.Published().After(b.Published()) }
+	case SortDownloads:
+		less = func(a, b Item) bool { return a.Downloads > b.Downloads }
+	default:
+		return out
+	}
+	sort.SliceStable(out, func(i, j int) bool { return less(out[i], out[j]) })
+	return out
+}
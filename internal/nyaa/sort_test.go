@@ -0,0 +1,36 @@
+package nyaa
+
+import "testing"
+
+func TestSortItems(t *testing.T) {
+	t.Parallel()
+
+	items := []Item{
+		{InfoHash: "a", Seeders: 5, Downloads: 900, Size: "300 MiB", PubDate: "Mon, 02 Oct 2023 10:00:00 -0000"},
+		{InfoHash: "b", Seeders: 50, Downloads: 10, Size: "1.2 GiB", PubDate: "Sun, 01 Oct 2023 10:00:00 -0000"},
+		{InfoHash: "c", Seeders: 20, Downloads: 100, Size: "700 MiB", PubDate: "Tue, 03 Oct 2023 10:00:00 -0000"},
+	}
+
+	tests := []struct {
+		key  SortKey
+		want string
+	}{
+		{SortScore, "abc"},
+		{SortSeeders, "bca"},
+		{SortSize, "bca"},
+		{SortDate, "cab"},
+		{SortDownloads, "acb"},
+	}
+	for _, tt := range tests {
+		var got string
+		for _, it := range SortItems(items, tt.key) {
+			got += it.InfoHash
+		}
+		if got != tt.want {
+			t.Errorf("SortItems(%s) = %s, want %s", tt.key.Label(), got, tt.want)
+		}
+	}
+	if items[0].InfoHash != "a" {
+		t.Error("SortItems modified its input")
+	}
+}
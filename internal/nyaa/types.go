@@ -3,7 +3,9 @@ package nyaa
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // RSS is the root structure for the nyaa.si RSS feed.
@@ -74,6 +76,49 @@ func (i Item) IsTrusted() bool {
 	}
 }
 
+// IsRemake reports whether the torrent is flagged as a remake by nyaa.si.
+func (i Item) IsRemake() bool {
+	switch strings.ToLower(strings.TrimSpace(i.Remake)) {
+	case "yes", "true", "1":
+		return true
+	default:
+		return false
+	}
+}
+
+// sizeUnits maps nyaa size suffixes to byte multipliers.
+var sizeUnits = map[string]float64{
+	"B":   1,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+}
+
+// SizeBytes parses the item's size (e.g. "1.4 GiB") into bytes, or 0 if the
+// size is missing or malformed.
+func (i Item) SizeBytes() int64 {
+	num, unit, ok := strings.Cut(strings.TrimSpace(i.Size), " ")
+	if !ok {
+		return 0
+	}
+	mult, known := sizeUnits[strings.TrimSpace(unit)]
+	n, err := strconv.ParseFloat(num, 64)
+	if !known || err != nil {
+		return 0
+	}
+	return int64(n * mult)
+}
+
+// Published parses the item's publication date, or returns the zero time.
+func (i Item) Published() time.Time {
+	t, err := time.Parse(time.RFC1123Z, strings.TrimSpace(i.PubDate))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 // Summary returns a compact line with key torrent metadata.
 func (i Item) Summary() string {
 	trusted := "No"
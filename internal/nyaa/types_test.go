@@ -63,3 +63,36 @@ func TestItemIsTrusted(t *testing.T) {
 		})
 	}
 }
+
+func TestItemSizeBytes(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		size string
+		want int64
+	}{
+		{size: "500 MiB", want: 500 << 20},
+		{size: "1.5 GiB", want: 3 << 29},
+		{size: "812 B", want: 812},
+		{size: "", want: 0},
+		{size: "lots", want: 0},
+	}
+
+	for _, tc := range cases {
+		if got := (Item{Size: tc.size}).SizeBytes(); got != tc.want {
+			t.Errorf("SizeBytes(%q) = %d, want %d", tc.size, got, tc.want)
+		}
+	}
+}
+
+func TestItemPublished(t *testing.T) {
+	t.Parallel()
+
+	got := Item{PubDate: "Sat, 07 Oct 2023 17:31:02 -0000"}.Published()
+	if got.IsZero() || got.Year() != 2023 || got.Day() != 7 {
+		t.Fatalf("Published() = %v, want 2023-10-07", got)
+	}
+	if !(Item{PubDate: "yesterday"}).Published().IsZero() {
+		t.Fatal("Published() of malformed date should be zero")
+	}
+}
@@ -0,0 +1,221 @@
+package nyaa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Details holds the extra information from a torrent's nyaa.si view page.
+type Details struct {
+	Submitter   string // user name, or "Anonymous"
+	Information string // free-form info field, usually a website
+	Description string // raw markdown, "" when the uploader left none
+	Files       []File
+	Comments    int
+}
+
+// File is one entry of a torrent's file list.
+type File struct {
+	Path string // slash-separated, including parent folders
+	Size string // as displayed by nyaa, e.g. "1.4 GiB"
+}
+
+// noDescription is the placeholder nyaa.si renders for empty descriptions.
+const noDescription = "#### No description."
+
+var (
+	reCommentCount = regexp.MustCompile(`Comments\s*-\s*(\d+)`)
+	reDownloadLink = regexp.MustCompile(`/download/(\d+)\.torrent$`)
+)
+
+// ViewURL returns the item's nyaa.si view page: its GUID, or a URL derived
+// from the .torrent download link. Returns "" when neither is usable.
+func ViewURL(it Item) string {
+	if guid := strings.TrimSpace(it.GUID); strings.Contains(guid, "/view/") {
+		return guid
+	}
+	link := strings.TrimSpace(it.Link)
+	if m := reDownloadLink.FindStringSubmatchIndex(link); m != nil {
+		return link[:m[0]] + "/view/" + link[m[2]:m[3]]
+	}
+	return ""
+}
+
+// FetchDetails downloads and parses the item's view page.
+func (c *Client) FetchDetails(ctx context.Context, it Item) (Details, error) {
+	viewURL := ViewURL(it)
+	if viewURL == "" {
+		return Details{}, fmt.Errorf("no view page for %q", it.Title)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, viewURL, nil)
+	if err != nil {
+		return Details{}, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return Details{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Details{}, fmt.Errorf("nyaa view page failed: status %d", resp.StatusCode)
+	}
+	return ParseDetails(resp.Body)
+}
+
+// FetchDetails uses the default client.
+func FetchDetails(ctx context.Context, it Item) (Details, error) {
+	return defaultClient.FetchDetails(ctx, it)
+}
+
+// ParseDetails extracts submitter, information, description, file list and
+// comment count from a nyaa.si view page.
+func ParseDetails(r io.Reader) (Details, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Details{}, fmt.Errorf("parse view page: %w", err)
+	}
+
+	var d Details
+	walk(doc, func(n *html.Node) bool {
+		switch {
+		case n.DataAtom == atom.Div && hasClass(n, "col-md-1"):
+			value := nextElement(n)
+			if value == nil {
+				return true
+			}
+			switch strings.TrimSpace(textContent(n)) {
+			case "Submitter:":
+				d.Submitter = collapseSpace(textContent(value))
+			case "Information:":
+				d.Information = collapseSpace(textContent(value))
+			}
+		case attr(n, "id") == "torrent-description":
+			d.Description = strings.TrimSpace(textContent(n))
+			if d.Description == noDescription {
+				d.Description = ""
+			}
+			return false
+		case n.DataAtom == atom.Div && hasClass(n, "torrent-file-list"):
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.DataAtom == atom.Ul {
+					d.Files = append(d.Files, parseFileList(c, "")...)
+				}
+			}
+			return false
+		case attr(n, "id") == "comments":
+			if m := reCommentCount.FindStringSubmatch(textContent(n)); m != nil {
+				d.Comments, _ = strconv.Atoi(m[1])
+			}
+			return false
+		}
+		return true
+	})
+	return d, nil
+}
+
+// parseFileList reads a file-list <ul>, prefixing paths with the parent
+// folder.
+func parseFileList(ul *html.Node, prefix string) []File {
+	var files []File
+	for li := ul.FirstChild; li != nil; li = li.NextSibling {
+		if li.DataAtom != atom.Li {
+			continue
+		}
+
+		var folder string
+		var nested *html.Node
+		var name strings.Builder
+		var size string
+		for c := li.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.DataAtom == atom.A && hasClass(c, "folder"):
+				folder = collapseSpace(textContent(c))
+			case c.DataAtom == atom.Ul:
+				nested = c
+			case c.DataAtom == atom.Span && hasClass(c, "file-size"):
+				size = strings.Trim(strings.TrimSpace(textContent(c)), "()")
+			case c.Type == html.TextNode:
+				name.WriteString(c.Data)
+			}
+		}
+
+		if nested != nil {
+			files = append(files, parseFileList(nested, prefix+folder+"/")...)
+			continue
+		}
+		if n := collapseSpace(name.String()); n != "" {
+			files = append(files, File{Path: prefix + n, Size: size})
+		}
+	}
+	return files
+}
+
+// walk visits n and its descendants depth-first; fn returns false to skip a
+// node's children.
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// nextElement returns the next sibling element of n, or nil.
+func nextElement(n *html.Node) *html.Node {
+	for s := n.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+// textContent concatenates all text beneath n.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+		return true
+	})
+	return b.String()
+}
+
+// collapseSpace trims s and folds internal whitespace runs to single spaces.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// attr returns the value of n's attribute key, or "".
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasClass reports whether n's class list contains class.
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
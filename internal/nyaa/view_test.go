@@ -0,0 +1,109 @@
+package nyaa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDetails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fixture string
+		want    Details
+	}{
+		{
+			fixture: "view_folder.html",
+			want: Details{
+				Submitter:   "subsplease",
+				Information: "https://subsplease.org",
+				Description: "Batch of the full first season.\n\n**Note:** episodes 27-28 were re-encoded & replaced.",
+				Files: []File{
+					{Path: "[SubsPlease] Sousou no Frieren (01-28) (1080p) [Batch]/[SubsPlease] Sousou no Frieren - 01 (1080p) [F02B9CEE].mkv", Size: "1.4 GiB"},
+					{Path: "[SubsPlease] Sousou no Frieren (01-28) (1080p) [Batch]/[SubsPlease] Sousou no Frieren - 02 (1080p) [E5A1C3D1].mkv", Size: "1.3 GiB"},
+					{Path: "[SubsPlease] Sousou no Frieren (01-28) (1080p) [Batch]/Extras/NCOP.mkv", Size: "120.5 MiB"},
+				},
+				Comments: 3,
+			},
+		},
+		{
+			fixture: "view_anonymous.html",
+			want: Details{
+				Submitter:   "Anonymous",
+				Information: "No information.",
+				Files:       []File{{Path: "Show Name - 05 [720p].mkv", Size: "350.0 MiB"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := os.Open(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			got, err := ParseDetails(f)
+			if err != nil {
+				t.Fatalf("ParseDetails returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDetails(%s)\n got %+v\nwant %+v", tt.fixture, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewURL(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		item Item
+		want string
+	}{
+		{Item{GUID: "https://nyaa.si/view/1234567"}, "https://nyaa.si/view/1234567"},
+		{Item{Link: "https://nyaa.si/download/1234567.torrent"}, "https://nyaa.si/view/1234567"},
+		{Item{Link: "magnet:?xt=urn:btih:abc"}, ""},
+	}
+	for _, tc := range cases {
+		if got := ViewURL(tc.item); got != tc.want {
+			t.Errorf("ViewURL(%+v) = %q, want %q", tc.item, got, tc.want)
+		}
+	}
+}
+
+func TestClientFetchDetails(t *testing.T) {
+	t.Parallel()
+
+	page, err := os.ReadFile(filepath.Join("testdata", "view_anonymous.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = w.Write(page)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.Client())
+	d, err := client.FetchDetails(context.Background(), Item{GUID: srv.URL + "/view/42"})
+	if err != nil {
+		t.Fatalf("FetchDetails returned error: %v", err)
+	}
+	if gotPath != "/view/42" {
+		t.Errorf("requested %q, want /view/42", gotPath)
+	}
+	if d.Submitter != "Anonymous" || len(d.Files) != 1 {
+		t.Errorf("unexpected details: %+v", d)
+	}
+}
@@ -0,0 +1,132 @@
+package player
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+const ipcTimeout = 2 * time.Second
+
+// ipcResponse is a reply to a JSON IPC command. Event messages share the
+// socket and carry no request_id.
+type ipcResponse struct {
+	RequestID int             `json:"request_id"`
+	Error     string          `json:"error"`
+	Data      json.RawMessage `json:"data"`
+	Event     string          `json:"event"`
+	Reason    string          `json:"reason"`
+}
+
+// EndReason reports why mpv stopped playing: "eof" when the file played to
+// the end, "quit" when the user closed it, or "" if unknown.
+func (s *Session) EndReason() string {
+	select {
+	case <-s.watchDone:
+	case <-time.After(ipcTimeout):
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.endReason
+}
+
+// watchEvents listens on the IPC socket for end-file events until mpv exits.
+func (s *Session) watchEvents() {
+	defer close(s.watchDone)
+
+	// mpv creates the socket shortly after it starts.
+	var conn net.Conn
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		c, err := net.Dial("unix", s.ipcPath)
+		if err == nil {
+			conn = c
+			break
+		}
+		select {
+		case <-s.exited:
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	if conn == nil {
+		return
+	}
+	defer conn.Close()
+
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		var resp ipcResponse
+		if err := json.Unmarshal(sc.Bytes(), &resp); err != nil {
+			continue
+		}
+		if resp.Event == "end-file" {
+			s.mu.Lock()
+			s.endReason = resp.Reason
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Position returns the playback offset and the length of the file.
+func (s *Session) Position() (offset, duration time.Duration, err error) {
+	var pos, dur float64
+	if err := s.getProperty("time-pos", &pos); err != nil {
+		return 0, 0, err
+	}
+	if err := s.getProperty("duration", &dur); err != nil {
+		return 0, 0, err
+	}
+	return seconds(pos), seconds(dur), nil
+}
+
+// seconds converts an mpv time property to a Duration.
+func seconds(v float64) time.Duration {
+	return time.Duration(v * float64(time.Second))
+}
+
+// getProperty reads an mpv property over the session's IPC socket into v.
+func (s *Session) getProperty(name string, v any) error {
+	if s.ipcPath == "" {
+		return fmt.Errorf("mpv ipc not available")
+	}
+
+	conn, err := net.DialTimeout("unix", s.ipcPath, ipcTimeout)
+	if err != nil {
+		return fmt.Errorf("connect mpv ipc: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ipcTimeout))
+
+	const requestID = 1
+	cmd := map[string]any{
+		"command":    []string{"get_property", name},
+		"request_id": requestID,
+	}
+	if err := json.NewEncoder(conn).Encode(cmd); err != nil {
+		return fmt.Errorf("send mpv command: %w", err)
+	}
+
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		var resp ipcResponse
+		if err := json.Unmarshal(sc.Bytes(), &resp); err != nil {
+			return fmt.Errorf("decode mpv reply: %w", err)
+		}
+		if resp.Event != "" || resp.RequestID != requestID {
+			continue
+		}
+		if resp.Error != "success" {
+			return fmt.Errorf("get %s: %s", name, resp.Error)
+		}
+		if err := json.Unmarshal(resp.Data, v); err != nil {
+			return fmt.Errorf("decode %s: %w", name, err)
+		}
+		return nil
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read mpv reply: %w", err)
+	}
+	return fmt.Errorf("mpv ipc closed")
+}
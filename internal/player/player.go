@@ -5,27 +5,31 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sync"
 	"time"
 )
 
-// TODO: use unique path per session when IPC is needed.
-const ipcSocket = "/tmp/ani-tui-mpv.sock"
-
 // Session manages an mpv process and the localhost HTTP server that feeds it.
 type Session struct {
-	cmd    *exec.Cmd
-	server *http.Server
-	done   chan error
+	cmd     *exec.Cmd
+	server  *http.Server
+	ipcDir  string // private directory holding the IPC socket
+	ipcPath string
+	done    chan error
+	exited  chan struct{} // closed once mpv has exited
+
+	watchDone chan struct{} // closed when the event watcher stops
+	mu        sync.Mutex
+	endReason string // reason from mpv's last end-file event
 }
 
 // Start launches a localhost HTTP proxy serving the reader, then starts mpv
-// pointed at the proxy URL. The mpvPath may be empty to use "mpv" from PATH.
-func Start(mpvPath string, reader io.ReadSeeker, filename string) (*Session, error) {
-	if mpvPath == "" {
-		mpvPath = "mpv"
-	}
-
+// pointed at the proxy URL, seeking to start if it is non-zero. The mpvPath
+// may be empty to use "mpv" from PATH.
+func Start(mpvPath string, reader io.ReadSeeker, filename string, start time.Duration) (*Session, error) {
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		return nil, fmt.Errorf("listen: %w", err)
@@ -41,26 +45,65 @@ func Start(mpvPath string, reader io.ReadSeeker, filename string) (*Session, err
 
 	url := fmt.Sprintf("http://127.0.0.1:%d/video", ln.Addr().(*net.TCPAddr).Port)
 
-	cmd := exec.Command(mpvPath,
-		"--input-ipc-server="+ipcSocket,
+	s, err := launch(mpvPath, url, start)
+	if err != nil {
+		srv.Close()
+		return nil, err
+	}
+	s.server = srv
+	return s, nil
+}
+
+// StartFile starts mpv directly on a local video file, bypassing the HTTP
+// proxy, seeking to start if it is non-zero. The mpvPath may be empty to use
+// "mpv" from PATH.
+func StartFile(mpvPath, path string, start time.Duration) (*Session, error) {
+	return launch(mpvPath, path, start)
+}
+
+// launch starts mpv on the given URL or path and begins waiting for it to exit.
+func launch(mpvPath, target string, start time.Duration) (*Session, error) {
+	if mpvPath == "" {
+		mpvPath = "mpv"
+	}
+
+	// Each session gets its own socket so concurrent instances don't collide.
+	ipcDir, err := os.MkdirTemp("", "ani-tui-mpv-*")
+	if err != nil {
+		return nil, fmt.Errorf("create ipc dir: %w", err)
+	}
+	ipcPath := filepath.Join(ipcDir, "mpv.sock")
+
+	args := []string{
+		"--input-ipc-server=" + ipcPath,
 		"--force-window=yes",
-		url,
-	)
+	}
+	if start > 0 {
+		args = append(args, fmt.Sprintf("--start=%.0f", start.Seconds()))
+	}
+	cmd := exec.Command(mpvPath, append(args, target)...)
 
 	if err := cmd.Start(); err != nil {
-		srv.Close()
+		os.RemoveAll(ipcDir)
 		return nil, fmt.Errorf("start mpv: %w", err)
 	}
 
 	s := &Session{
-		cmd:    cmd,
-		server: srv,
-		done:   make(chan error, 1),
+		cmd:     cmd,
+		ipcDir:  ipcDir,
+		ipcPath: ipcPath,
+		done:    make(chan error, 1),
+		exited:  make(chan struct{}),
+
+		watchDone: make(chan struct{}),
 	}
 
 	go func() {
-		s.done <- cmd.Wait()
+		err := cmd.Wait()
+		close(s.exited)
+		s.done <- err
 	}()
+	go s.watchEvents()
 
 	return s, nil
 }
@@ -70,19 +113,25 @@ func (s *Session) Wait() <-chan error {
 	return s.done
 }
 
-// Close kills mpv if still running, shuts down the HTTP server, and waits for
-// the process goroutine to finish.
+// Close kills mpv if still running, shuts down the HTTP server, removes the
+// IPC socket, and waits for the process goroutine to finish.
 func (s *Session) Close() {
 	if s.cmd != nil && s.cmd.Process != nil {
-		s.cmd.Process.Kill()
-		// Drain done channel so the goroutine can exit.
 		select {
-		case <-s.done:
-		case <-time.After(3 * time.Second):
+		case <-s.exited:
+		default:
+			s.cmd.Process.Kill()
+			select {
+			case <-s.exited:
+			case <-time.After(3 * time.Second):
+			}
 		}
 	}
 
 	if s.server != nil {
 		s.server.Close()
 	}
+	if s.ipcDir != "" {
+		os.RemoveAll(s.ipcDir)
+	}
 }
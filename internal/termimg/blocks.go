@@ -0,0 +1,26 @@
+package termimg
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// renderBlocks draws img, which must be cols×rows*2 pixels, with one "▀" per
+// cell: the foreground colours the top pixel and the background the bottom.
+func renderBlocks(img *image.RGBA, cols, rows int) string {
+	var sb strings.Builder
+	for row := range rows {
+		if row > 0 {
+			sb.WriteByte('\n')
+		}
+		for col := range cols {
+			top := img.RGBAAt(col, row*2)
+			bottom := img.RGBAAt(col, row*2+1)
+			fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀",
+				top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+		}
+		sb.WriteString("\x1b[0m")
+	}
+	return sb.String()
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package termimg
+
+// CellSize returns the default cell size; only Unix terminals report theirs.
+func CellSize() (width, height int) {
+	return DefaultCellWidth, DefaultCellHeight
+}
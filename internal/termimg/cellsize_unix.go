@@ -0,0 +1,19 @@
+//go:build unix
+
+package termimg
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// CellSize returns the size of a terminal cell in pixels, or the defaults
+// when the terminal doesn't report it.
+func CellSize() (width, height int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 || ws.Xpixel == 0 || ws.Ypixel == 0 {
+		return DefaultCellWidth, DefaultCellHeight
+	}
+	return int(ws.Xpixel / ws.Col), int(ws.Ypixel / ws.Row)
+}
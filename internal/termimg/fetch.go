@@ -0,0 +1,56 @@
+package termimg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg" // AniList covers are JPEG or PNG
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"github.com/rayanxn/ani-tui/internal/cache"
+)
+
+// cacheNamespace holds downloaded images, keyed by URL. Image URLs change
+// when the image does, so entries never go stale.
+const cacheNamespace = "images"
+
+// maxImageBytes bounds a download; covers are a few hundred KiB.
+const maxImageBytes = 8 << 20
+
+// Fetch downloads and decodes the image at url, keeping the download in c so
+// later calls, including offline ones, don't hit the network.
+func Fetch(ctx context.Context, c *cache.Cache, url string) (image.Image, error) {
+	if data, _, ok := c.GetBytes(cacheNamespace, url); ok {
+		if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+			return img, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch image: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read image: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+
+	_ = c.PutBytes(cacheNamespace, url, data) // best-effort
+	return img, nil
+}
@@ -0,0 +1,70 @@
+package termimg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+// kittyPlaceholder is the character kitty replaces with part of an image.
+const kittyPlaceholder = '\U0010EEEE'
+
+// kittyChunk is the largest base64 payload kitty accepts per escape.
+const kittyChunk = 4096
+
+// kittyDiacritics encode row and column numbers on placeholders, in the
+// order kitty assigns them.
+var kittyDiacritics = []rune{
+	0x0305, 0x030D, 0x030E, 0x0310, 0x0312, 0x033D, 0x033E, 0x033F,
+	0x0346, 0x034A, 0x034B, 0x034C, 0x0350, 0x0351, 0x0352, 0x0357,
+	0x035B, 0x0363, 0x0364, 0x0365, 0x0366, 0x0367, 0x0368, 0x0369,
+	0x036A, 0x036B, 0x036C, 0x036D, 0x036E, 0x036F, 0x0483, 0x0484,
+	0x0485, 0x0486, 0x0487, 0x0592, 0x0593, 0x0594, 0x0595, 0x0597,
+	0x0598, 0x0599, 0x059C, 0x059D, 0x059E, 0x059F, 0x05A0, 0x05A1,
+}
+
+// renderKitty transmits img as a virtual placement and draws it with Unicode
+// placeholders, so the image lives in ordinary text cells: it moves and
+// disappears with the text around it. The transmission rides on the first
+// line and is resent whenever that line is redrawn.
+func renderKitty(img *image.RGBA, cols, rows, id int) string {
+	rows = min(rows, len(kittyDiacritics))
+	cols = min(cols, len(kittyDiacritics))
+	id = max(1, min(id, 255))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var sb strings.Builder
+	for i := 0; i < len(payload); i += kittyChunk {
+		chunk := payload[i:min(i+kittyChunk, len(payload))]
+		more := 0
+		if i+kittyChunk < len(payload) {
+			more = 1
+		}
+		if i == 0 {
+			// q=2 silences replies, which would otherwise arrive as input.
+			fmt.Fprintf(&sb, "\x1b_Ga=T,f=100,t=d,i=%d,U=1,c=%d,r=%d,q=2,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+
+	// The foreground colour carries the image ID. Only the first cell of a
+	// row needs diacritics; kitty numbers the following columns itself.
+	rest := strings.Repeat(string(kittyPlaceholder), cols-1)
+	for row := range rows {
+		if row > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "\x1b[38;5;%dm%c%c%c%s\x1b[39m",
+			id, kittyPlaceholder, kittyDiacritics[row], kittyDiacritics[0], rest)
+	}
+	return sb.String()
+}
@@ -0,0 +1,115 @@
+package termimg
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// renderSixel draws img, which must be rows*cellHeight pixels tall, as one
+// sixel image per line. Each line first blanks its cells, then draws its
+// strip from the saved cursor position, then skips the cursor past the
+// image so text after it on the line lands in the right column.
+func renderSixel(img *image.RGBA, cols, rows, cellHeight int) string {
+	blank := strings.Repeat(" ", cols)
+	var sb strings.Builder
+	for row := range rows {
+		if row > 0 {
+			sb.WriteByte('\n')
+		}
+		strip := img.SubImage(image.Rect(0, row*cellHeight, img.Bounds().Dx(), (row+1)*cellHeight)).(*image.RGBA)
+		sb.WriteString("\x1b7" + blank + "\x1b8")
+		sb.WriteString(encodeSixel(strip))
+		fmt.Fprintf(&sb, "\x1b8\x1b[%dC", cols)
+	}
+	return sb.String()
+}
+
+// encodeSixel encodes img as a sixel image with a transparent background,
+// quantizing colours to a 6×6×6 cube.
+func encodeSixel(img *image.RGBA) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// Palette index of each pixel, or -1 when transparent.
+	idx := make([]int, w*h)
+	var used [216]bool
+	for y := range h {
+		for x := range w {
+			c := img.RGBAAt(b.Min.X+x, b.Min.Y+y)
+			if c.A < 128 {
+				idx[y*w+x] = -1
+				continue
+			}
+			i := cube(c.R)*36 + cube(c.G)*6 + cube(c.B)
+			idx[y*w+x] = i
+			used[i] = true
+		}
+	}
+
+	var sb strings.Builder
+	// P2=1 leaves unset pixels transparent; raster attributes give the size.
+	fmt.Fprintf(&sb, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	for i, ok := range used {
+		if ok {
+			// Register colours are percentages.
+			fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+		}
+	}
+
+	for band := 0; band < h; band += 6 {
+		if band > 0 {
+			sb.WriteByte('-') // next band
+		}
+		first := true
+		for c, ok := range used {
+			if !ok {
+				continue
+			}
+			line := make([]byte, w)
+			set := false
+			for x := range w {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < h; dy++ {
+					if idx[(band+dy)*w+x] == c {
+						bits |= 1 << dy
+					}
+				}
+				line[x] = 63 + bits
+				set = set || bits != 0
+			}
+			if !set {
+				continue
+			}
+			if !first {
+				sb.WriteByte('$') // back to the start of the band
+			}
+			first = false
+			fmt.Fprintf(&sb, "#%d", c)
+			writeRuns(&sb, line)
+		}
+	}
+	sb.WriteString("\x1b\\")
+	return sb.String()
+}
+
+// writeRuns writes sixel characters using "!n" repeats for runs.
+func writeRuns(sb *strings.Builder, line []byte) {
+	for i := 0; i < len(line); {
+		j := i
+		for j < len(line) && line[j] == line[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(sb, "!%d%c", n, line[i])
+		} else {
+			sb.Write(line[i:j])
+		}
+		i = j
+	}
+}
+
+// cube maps a colour channel to one of six levels.
+func cube(v uint8) int {
+	return (int(v)*5 + 127) / 255
+}
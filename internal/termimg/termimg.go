@@ -0,0 +1,168 @@
+// Package termimg draws images in the terminal, using the kitty graphics
+// protocol or sixel where the terminal supports them and coloured half blocks
+// otherwise.
+//
+// Every protocol renders to a block of text lines that can be laid out like
+// any other: each line is exactly as wide as the image and draws its own part
+// of it, so redrawing one line never leaves the rest of the image broken.
+package termimg
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Protocol is a way of drawing images in a terminal.
+type Protocol int
+
+const (
+	None   Protocol = iota // images are not shown
+	Blocks                 // half-block characters in truecolor
+	Sixel
+	Kitty
+)
+
+// String returns the protocol's config name.
+func (p Protocol) String() string {
+	switch p {
+	case Blocks:
+		return "blocks"
+	case Sixel:
+		return "sixel"
+	case Kitty:
+		return "kitty"
+	default:
+		return "off"
+	}
+}
+
+// ParseProtocol reads a config value. "" and "auto" report ok=false so the
+// caller falls back to Detect.
+func ParseProtocol(s string) (Protocol, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "off", "none":
+		return None, true
+	case "blocks":
+		return Blocks, true
+	case "sixel":
+		return Sixel, true
+	case "kitty":
+		return Kitty, true
+	}
+	return None, false
+}
+
+// Detect guesses the best protocol from the environment, read with getenv.
+// Terminal multiplexers don't pass graphics through, so only blocks are used
+// inside them.
+func Detect(getenv func(string) string) Protocol {
+	term := getenv("TERM")
+	program := getenv("TERM_PROGRAM")
+	truecolor := getenv("COLORTERM") == "truecolor" || getenv("COLORTERM") == "24bit"
+
+	if getenv("TMUX") != "" || strings.HasPrefix(term, "screen") {
+		if truecolor {
+			return Blocks
+		}
+		return None
+	}
+	switch {
+	// Only terminals that support kitty's Unicode placeholders qualify.
+	case term == "xterm-kitty", getenv("KITTY_WINDOW_ID") != "",
+		term == "xterm-ghostty", program == "ghostty":
+		return Kitty
+	case strings.Contains(term, "sixel"), strings.HasPrefix(term, "foot"),
+		term == "mlterm", program == "WezTerm", getenv("KONSOLE_VERSION") != "":
+		return Sixel
+	case truecolor:
+		return Blocks
+	}
+	return None
+}
+
+// Options controls how an image is rendered.
+type Options struct {
+	Protocol Protocol
+	// Cols and Rows are the size of the block in terminal cells.
+	Cols, Rows int
+	// CellWidth and CellHeight are the size of a cell in pixels, used to
+	// scale the image for kitty and sixel. Zero uses DefaultCellSize.
+	CellWidth, CellHeight int
+	// ID identifies the image to kitty, from 1 to 255. Rendering another
+	// image with the same ID replaces it.
+	ID int
+}
+
+// DefaultCellSize is assumed when the terminal doesn't report its cell size.
+const (
+	DefaultCellWidth  = 10
+	DefaultCellHeight = 20
+)
+
+// Render draws img into a block of o.Rows lines, each o.Cols cells wide. It
+// returns "" for None or an empty block.
+func Render(img image.Image, o Options) string {
+	if o.Cols <= 0 || o.Rows <= 0 || img == nil {
+		return ""
+	}
+	if o.CellWidth <= 0 || o.CellHeight <= 0 {
+		o.CellWidth, o.CellHeight = DefaultCellWidth, DefaultCellHeight
+	}
+	switch o.Protocol {
+	case Blocks:
+		return renderBlocks(resize(img, o.Cols, o.Rows*2), o.Cols, o.Rows)
+	case Sixel:
+		return renderSixel(resize(img, o.Cols*o.CellWidth, o.Rows*o.CellHeight), o.Cols, o.Rows, o.CellHeight)
+	case Kitty:
+		return renderKitty(resize(img, o.Cols*o.CellWidth, o.Rows*o.CellHeight), o.Cols, o.Rows, o.ID)
+	}
+	return ""
+}
+
+// FitRows returns how many rows an image cols cells wide needs to keep its
+// aspect ratio with the given cell size.
+func FitRows(img image.Image, cols, cellWidth, cellHeight int) int {
+	b := img.Bounds()
+	if b.Dx() == 0 || cols <= 0 {
+		return 0
+	}
+	if cellWidth <= 0 || cellHeight <= 0 {
+		cellWidth, cellHeight = DefaultCellWidth, DefaultCellHeight
+	}
+	px := float64(cols*cellWidth) * float64(b.Dy()) / float64(b.Dx())
+	return max(1, int(px/float64(cellHeight)+0.5))
+}
+
+// resize scales img to w×h pixels, averaging the source pixels that fall in
+// each destination pixel.
+func resize(img image.Image, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := img.Bounds()
+	if b.Empty() {
+		return dst
+	}
+	for y := range h {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := range w {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a = r+cr, g+cg, bl+cb, a+ca
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(bl / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,133 @@
+package termimg
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// solid returns a w×h image filled with c.
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+var (
+	red  = color.RGBA{R: 255, A: 255}
+	blue = color.RGBA{B: 255, A: 255}
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Protocol
+	}{
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, Kitty},
+		{"ghostty", map[string]string{"TERM_PROGRAM": "ghostty", "COLORTERM": "truecolor"}, Kitty},
+		{"foot", map[string]string{"TERM": "foot"}, Sixel},
+		{"wezterm", map[string]string{"TERM_PROGRAM": "WezTerm"}, Sixel},
+		{"truecolor", map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, Blocks},
+		{"kitty in tmux", map[string]string{"TERM": "tmux-256color", "KITTY_WINDOW_ID": "1", "TMUX": "/tmp/x", "COLORTERM": "truecolor"}, Blocks},
+		{"basic", map[string]string{"TERM": "xterm"}, None},
+	}
+	for _, tt := range tests {
+		got := Detect(func(k string) string { return tt.env[k] })
+		if got != tt.want {
+			t.Errorf("%s: Detect() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRender_Blocks(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewRGBA(image.Rect(0, 0, 1, 2))
+	img.SetRGBA(0, 0, red)
+	img.SetRGBA(0, 1, blue)
+
+	got := Render(img, Options{Protocol: Blocks, Cols: 1, Rows: 1})
+	want := "\x1b[38;2;255;0;0;48;2;0;0;255m▀\x1b[0m"
+	if got != want {
+		t.Fatalf("Render = %q, want %q", got, want)
+	}
+}
+
+func TestEncodeSixel(t *testing.T) {
+	t.Parallel()
+
+	got := encodeSixel(solid(2, 1, red))
+	want := "\x1bP0;1;0q\"1;1;2;1#180;2;100;0;0#180@@\x1b\\"
+	if got != want {
+		t.Fatalf("encodeSixel = %q, want %q", got, want)
+	}
+
+	// Seven rows span two bands; long runs are compressed.
+	got = encodeSixel(solid(5, 7, blue))
+	want = "\x1bP0;1;0q\"1;1;5;7#5;2;0;0;100#5!5~-#5!5@\x1b\\"
+	if got != want {
+		t.Fatalf("encodeSixel = %q, want %q", got, want)
+	}
+}
+
+// TestRender_LayoutWidth checks every protocol yields Rows lines that
+// lipgloss measures as Cols wide, so the block lays out like plain text.
+func TestRender_LayoutWidth(t *testing.T) {
+	t.Parallel()
+
+	img := solid(40, 60, red)
+	for _, p := range []Protocol{Blocks, Sixel, Kitty} {
+		out := Render(img, Options{Protocol: p, Cols: 4, Rows: 3, CellWidth: 2, CellHeight: 4, ID: 9})
+		lines := strings.Split(out, "\n")
+		if len(lines) != 3 {
+			t.Fatalf("%v: got %d lines, want 3", p, len(lines))
+		}
+		for i, l := range lines {
+			if w := lipgloss.Width(l); w != 4 {
+				t.Errorf("%v: line %d is %d cells wide, want 4", p, i, w)
+			}
+		}
+	}
+}
+
+func TestRender_Kitty(t *testing.T) {
+	t.Parallel()
+
+	out := Render(solid(4, 4, red), Options{Protocol: Kitty, Cols: 2, Rows: 2, ID: 7})
+	if !strings.HasPrefix(out, "\x1b_Ga=T,f=100,t=d,i=7,U=1,c=2,r=2,q=2,m=0;") {
+		t.Fatalf("missing transmission header: %q", out[:min(len(out), 60)])
+	}
+	lines := strings.Split(out, "\n")
+	// Row 2's first cell carries the second row diacritic and column 0.
+	want := "\x1b[38;5;7m\U0010EEEE̍̅\U0010EEEE\x1b[39m"
+	if lines[1] != want {
+		t.Fatalf("row 2 = %q, want %q", lines[1], want)
+	}
+}
+
+func TestRender_None(t *testing.T) {
+	t.Parallel()
+
+	if out := Render(solid(2, 2, red), Options{Protocol: None, Cols: 2, Rows: 2}); out != "" {
+		t.Fatalf("Render(None) = %q, want empty", out)
+	}
+}
+
+func TestFitRows(t *testing.T) {
+	t.Parallel()
+
+	// A 2:3 cover 20 cells wide with 1:2 cells is 15 rows tall.
+	if got := FitRows(solid(200, 300, red), 20, 10, 20); got != 15 {
+		t.Fatalf("FitRows = %d, want 15", got)
+	}
+}
@@ -1,22 +1,71 @@
 package torrent
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+	"golang.org/x/time/rate"
 )
 
-// Client wraps an anacrolix/torrent client for streaming.
+// defaultThrottleRate caps transfers while throttled when no rate is set.
+const defaultThrottleRate = 256 * 1024 // bytes/sec
+
+// rateBurst is the limiter burst. It must hold a whole 16 KiB chunk and the
+// client's per-connection read buffer, and stays fixed when limits change.
+const rateBurst = 1 << 20
+
+// ClientOptions configures bandwidth and seeding for NewClient.
+type ClientOptions struct {
+	// DownloadDir is where torrent data is stored. Empty uses a temporary
+	// directory removed on Close.
+	DownloadDir string
+
+	// DownloadRate and UploadRate cap transfer speed in bytes/sec; 0 is
+	// unlimited.
+	DownloadRate int64
+	UploadRate   int64
+
+	// ThrottleRate caps both directions while throttled (see SetThrottled).
+	// 0 uses 256 KiB/s.
+	ThrottleRate int64
+
+	// ListenPort for incoming peer connections; 0 keeps the library default.
+	ListenPort int
+
+	Seed SeedPolicy
+}
+
+// Client wraps an anacrolix/torrent client shared by streaming and the
+// download queue.
 type Client struct {
 	client      *torrent.Client
-	activeTor   *torrent.Torrent
 	downloadDir string
 	ownsTempDir bool
+
+	opts          ClientOptions
+	downloadLimit *rate.Limiter
+	uploadLimit   *rate.Limiter
+
+	mu         sync.Mutex
+	activeTor  *torrent.Torrent       // torrent being streamed
+	activeFile *torrent.File          // file being streamed from activeTor
+	pinned     map[metainfo.Hash]bool // torrents held by the download queue
+	seeding    map[metainfo.Hash]seedingTorrent
+	prefetched *torrent.Torrent // next episode warmed up by Prefetch
+	throttled  bool
+	done       chan struct{}
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
 }
 
-// NewClient creates a torrent client configured for streaming (no seeding).
-func NewClient(downloadDir string) (*Client, error) {
+// NewClient creates a torrent client with the given bandwidth limits and
+// seeding policy.
+func NewClient(opts ClientOptions) (*Client, error) {
+	downloadDir := opts.DownloadDir
 	ownsTempDir := false
 	if downloadDir == "" {
 		dir, err := os.MkdirTemp("", "ani-tui-*")
@@ -27,9 +76,20 @@ func NewClient(downloadDir string) (*Client, error) {
 		ownsTempDir = true
 	}
 
+	if opts.ThrottleRate <= 0 {
+		opts.ThrottleRate = defaultThrottleRate
+	}
+	downloadLimit := rate.NewLimiter(limitFor(opts.DownloadRate), rateBurst)
+	uploadLimit := rate.NewLimiter(limitFor(opts.UploadRate), rateBurst)
+
 	cfg := torrent.NewDefaultClientConfig()
 	cfg.DataDir = downloadDir
-	cfg.Seed = false
+	cfg.Seed = opts.Seed.Enabled
+	cfg.DownloadRateLimiter = downloadLimit
+	cfg.UploadRateLimiter = uploadLimit
+	if opts.ListenPort > 0 {
+		cfg.ListenPort = opts.ListenPort
+	}
 
 	tc, err := torrent.NewClient(cfg)
 	if err != nil {
@@ -39,23 +99,171 @@ func NewClient(downloadDir string) (*Client, error) {
 		return nil, fmt.Errorf("create torrent client: %w", err)
 	}
 
-	return &Client{
-		client:      tc,
-		downloadDir: downloadDir,
-		ownsTempDir: ownsTempDir,
-	}, nil
+	c := &Client{
+		client:        tc,
+		downloadDir:   downloadDir,
+		ownsTempDir:   ownsTempDir,
+		opts:          opts,
+		downloadLimit: downloadLimit,
+		uploadLimit:   uploadLimit,
+		pinned:        make(map[metainfo.Hash]bool),
+		seeding:       make(map[metainfo.Hash]seedingTorrent),
+		done:          make(chan struct{}),
+	}
+	if opts.Seed.Enabled {
+		c.wg.Add(1)
+		go c.runSeedPolicy()
+	}
+	return c, nil
+}
+
+// StopStream releases the active streaming torrent unless the download queue
+// or a prefetch also holds it. A fully downloaded file may keep seeding under
+// the seed policy. The client itself stays open for later streams.
+func (c *Client) StopStream() {
+	c.mu.Lock()
+	t, f := c.activeTor, c.activeFile
+	c.activeTor, c.activeFile = nil, nil
+	held := t != nil && (c.pinned[t.InfoHash()] || c.prefetched == t)
+	c.mu.Unlock()
+
+	if t == nil || held {
+		return
+	}
+	if f != nil && f.BytesCompleted() >= f.Length() {
+		c.retire(t)
+	} else {
+		c.drop(t)
+	}
+}
+
+// ErrTorrentInUse is returned by DropTorrent for a torrent that is streaming
+// or held by the download queue.
+var ErrTorrentInUse = errors.New("torrent is streaming or downloading")
+
+// DropTorrent removes the torrent with the given hex info hash from the
+// client, ending any seeding or prefetch, so its files can be deleted. A
+// torrent the client does not have is not an error.
+func (c *Client) DropTorrent(infoHash string) error {
+	var h metainfo.Hash
+	if err := h.FromHexString(infoHash); err != nil {
+		return fmt.Errorf("parse info hash: %w", err)
+	}
+	t, ok := c.client.Torrent(h)
+	if !ok {
+		return nil
+	}
+	if c.isPinned(h) || c.isStreaming(t) {
+		return ErrTorrentInUse
+	}
+	c.mu.Lock()
+	if c.prefetched == t {
+		c.prefetched = nil
+	}
+	c.mu.Unlock()
+	c.drop(t)
+	return nil
 }
 
-// Close drops the active torrent and closes the underlying client.
+// SetThrottled caps download and upload at the throttle rate, or restores
+// the configured limits.
+func (c *Client) SetThrottled(on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.throttled = on
+
+	down, up := c.opts.DownloadRate, c.opts.UploadRate
+	if on {
+		down = capRate(down, c.opts.ThrottleRate)
+		up = capRate(up, c.opts.ThrottleRate)
+	}
+	c.downloadLimit.SetLimit(limitFor(down))
+	c.uploadLimit.SetLimit(limitFor(up))
+}
+
+// Throttled reports whether SetThrottled(true) is in effect.
+func (c *Client) Throttled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.throttled
+}
+
+// ThrottleRate returns the throttled cap in bytes/sec.
+func (c *Client) ThrottleRate() int64 {
+	return c.opts.ThrottleRate
+}
+
+// Close drops all torrents and closes the underlying client. Calls after the
+// first do nothing.
 func (c *Client) Close() {
-	if c.activeTor != nil {
-		c.activeTor.Drop()
-		c.activeTor = nil
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.wg.Wait()
+		c.StopStream()
+		if c.client != nil {
+			c.client.Close()
+		}
+		if c.ownsTempDir {
+			os.RemoveAll(c.downloadDir)
+		}
+	})
+}
+
+// DownloadDir returns the directory torrent data is written to.
+func (c *Client) DownloadDir() string {
+	return c.downloadDir
+}
+
+// pin marks a torrent as owned by the download queue so StopStream keeps it.
+func (c *Client) pin(h metainfo.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pinned[h] = true
+}
+
+// unpin releases a torrent from the download queue.
+func (c *Client) unpin(h metainfo.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pinned, h)
+}
+
+// isPinned reports whether the download queue holds the torrent.
+func (c *Client) isPinned(h metainfo.Hash) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pinned[h]
+}
+
+// isPrefetched reports whether t is the torrent warmed up by Prefetch.
+func (c *Client) isPrefetched(t *torrent.Torrent) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.prefetched == t
+}
+
+// isStreaming reports whether t is the torrent currently being streamed.
+func (c *Client) isStreaming(t *torrent.Torrent) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.activeTor != nil && c.activeTor.InfoHash() == t.InfoHash()
+}
+
+// limitFor converts a bytes/sec cap into a limiter rate; 0 is unlimited.
+func limitFor(bytesPerSec int64) rate.Limit {
+	if bytesPerSec <= 0 {
+		return rate.Inf
 	}
-	if c.client != nil {
-		c.client.Close()
+	return rate.Limit(bytesPerSec)
+}
+
+// capRate returns the lower of two bytes/sec caps, treating 0 as unlimited.
+func capRate(limit, ceiling int64) int64 {
+	if ceiling <= 0 {
+		return limit
 	}
-	if c.ownsTempDir {
-		os.RemoveAll(c.downloadDir)
+	if limit <= 0 || limit > ceiling {
+		return ceiling
 	}
+	return limit
 }
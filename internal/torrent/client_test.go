@@ -0,0 +1,26 @@
+package torrent
+
+import "testing"
+
+func TestCapRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		limit, ceiling int64
+		want           int64
+	}{
+		{0, 500, 500},
+		{-1, 500, 500},
+		{0, 0, 0},
+		{300, 0, 300},
+		{300, -1, 300},
+		{300, 500, 300},
+		{500, 300, 300},
+		{400, 400, 400},
+	}
+	for _, tt := range tests {
+		if got := capRate(tt.limit, tt.ceiling); got != tt.want {
+			t.Errorf("capRate(%d, %d) = %d, want %d", tt.limit, tt.ceiling, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,677 @@
+package torrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+
+	"github.com/rayanxn/ani-tui/internal/fsutil"
+)
+
+// Priority orders queued downloads. Higher priorities start first and have
+// their pieces requested ahead of normal ones.
+type Priority int
+
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+// String returns a short label for the priority.
+func (p Priority) String() string {
+	switch {
+	case p > PriorityNormal:
+		return "high"
+	case p < PriorityNormal:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// ItemState is the lifecycle state of a queued download.
+type ItemState string
+
+const (
+	StateQueued    ItemState = "queued"
+	StateActive    ItemState = "downloading"
+	StatePaused    ItemState = "paused"
+	StateCompleted ItemState = "completed"
+	StateFailed    ItemState = "failed"
+)
+
+// QueueFile is a finished video file belonging to a queued torrent.
+type QueueFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// QueueItem is one torrent in the download queue.
+type QueueItem struct {
+	ID             string      `json:"id"`
+	Source         string      `json:"source"`
+	Title          string      `json:"title"`
+	MediaID        int         `json:"media_id"`
+	Episode        int         `json:"episode,omitempty"` // 0 for batches
+	Batch          bool        `json:"batch,omitempty"`   // download every video file
+	Priority       Priority    `json:"priority"`
+	State          ItemState   `json:"state"`
+	InfoHash       string      `json:"info_hash,omitempty"`
+	BytesCompleted int64       `json:"bytes_completed"`
+	BytesTotal     int64       `json:"bytes_total"`
+	Files          []QueueFile `json:"files,omitempty"`
+	Err            string      `json:"error,omitempty"`
+	AddedAt        time.Time   `json:"added_at"`
+
+	// Speed is the current download rate in bytes per second (not persisted).
+	Speed int64 `json:"-"`
+}
+
+// Progress returns the download progress as a float between 0.0 and 1.0.
+func (it QueueItem) Progress() float64 {
+	if it.BytesTotal == 0 {
+		return 0
+	}
+	return float64(it.BytesCompleted) / float64(it.BytesTotal)
+}
+
+// ManagerOptions configures a Manager.
+type ManagerOptions struct {
+	// MaxActive caps concurrent downloads; values <= 0 use a default of 2.
+	MaxActive int
+	// DiskQuota caps the total size of active and completed items in bytes;
+	// 0 means unlimited.
+	DiskQuota int64
+	// OnComplete, if set, is called from the manager goroutine whenever an
+	// item finishes downloading. An error is kept on the item so the queue
+	// shows it.
+	OnComplete func(QueueItem) error
+}
+
+const (
+	defaultMaxActive = 2
+	queueTick        = time.Second
+)
+
+// ErrAlreadyQueued is returned by Enqueue for a source that is already queued.
+var ErrAlreadyQueued = errors.New("already in download queue")
+
+// queueClient is the part of the torrent client the Manager drives. *Client
+// implements it; tests substitute a fake.
+type queueClient interface {
+	// fetch adds the torrent for source and waits for its metadata.
+	fetch(ctx context.Context, source string) (queueTorrent, error)
+	// hold keeps a queued torrent loaded when a stream of it stops.
+	hold(t queueTorrent)
+	// finish releases a downloaded torrent to the seed policy.
+	finish(t queueTorrent)
+	// abandon releases a torrent that is no longer queued, dropping it
+	// unless it is also streaming.
+	abandon(t queueTorrent)
+	DownloadDir() string
+}
+
+// queueTorrent is a torrent added by the download queue.
+type queueTorrent interface {
+	InfoHash() metainfo.Hash
+	files() []queueFile
+}
+
+// queueFile is one file of a queueTorrent; *torrent.File implements it.
+type queueFile interface {
+	Path() string
+	Length() int64
+	BytesCompleted() int64
+	SetPriority(torrent.PiecePriority)
+}
+
+// Manager downloads queued torrents in the background on a shared Client,
+// persisting the queue so it survives restarts.
+type Manager struct {
+	client queueClient
+	path   string
+	opts   ManagerOptions
+
+	mu       sync.Mutex
+	items    []*QueueItem
+	running  map[string]queueTorrent       // by item ID, metadata known
+	starting map[string]context.CancelFunc // by item ID, waiting for metadata
+	closed   bool                          // set by Close; nothing more starts
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewManager loads the queue persisted at statePath (which may not exist
+// yet) and starts downloading in the background. Items that were active when
+// the app last exited are re-queued.
+func NewManager(client *Client, statePath string, opts ManagerOptions) (*Manager, error) {
+	m, err := newManager(client, statePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	m.wg.Add(1)
+	go m.run()
+	return m, nil
+}
+
+// newManager loads the queue without starting the background loop.
+func newManager(client queueClient, statePath string, opts ManagerOptions) (*Manager, error) {
+	if opts.MaxActive <= 0 {
+		opts.MaxActive = defaultMaxActive
+	}
+
+	m := &Manager{
+		client:   client,
+		path:     statePath,
+		opts:     opts,
+		running:  make(map[string]queueTorrent),
+		starting: make(map[string]context.CancelFunc),
+		done:     make(chan struct{}),
+	}
+
+	if statePath != "" {
+		data, err := os.ReadFile(statePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("read download queue: %w", err)
+		default:
+			if err := json.Unmarshal(data, &m.items); err != nil {
+				return nil, fmt.Errorf("parse download queue: %w", err)
+			}
+		}
+	}
+	for _, it := range m.items {
+		if it.State == StateActive {
+			it.State = StateQueued
+		}
+	}
+	return m, nil
+}
+
+// Enqueue adds a download to the end of the queue. A source already queued
+// is refused, unless it completed and its files have since been deleted.
+func (m *Manager) Enqueue(item QueueItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, it := range m.items {
+		if it.Source != item.Source || it.State == StateFailed {
+			continue
+		}
+		if it.State == StateCompleted && !it.onDisk() {
+			// Deleted since it finished: the new download replaces it.
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			break
+		}
+		return ErrAlreadyQueued
+	}
+
+	item.ID = strconv.FormatInt(time.Now().UnixNano(), 36)
+	item.State = StateQueued
+	item.AddedAt = time.Now()
+	m.items = append(m.items, &item)
+	return m.saveLocked()
+}
+
+// Items returns a snapshot of the queue in display order.
+func (m *Manager) Items() []QueueItem {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]QueueItem, len(m.items))
+	for i, it := range m.items {
+		out[i] = *it
+	}
+	return out
+}
+
+// TogglePause pauses an active or queued item, or re-queues a paused or
+// failed one.
+func (m *Manager) TogglePause(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	it := m.findLocked(id)
+	if it == nil {
+		return nil
+	}
+	switch it.State {
+	case StateQueued, StateActive:
+		m.stopLocked(it)
+		it.State = StatePaused
+		it.Speed = 0
+	case StatePaused, StateFailed:
+		it.State = StateQueued
+		it.Err = ""
+	default:
+		return nil
+	}
+	return m.saveLocked()
+}
+
+// Cancel stops an item and removes it from the queue. Files that were
+// already written stay on disk.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, it := range m.items {
+		if it.ID == id {
+			m.stopLocked(it)
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			return m.saveLocked()
+		}
+	}
+	return nil
+}
+
+// Move shifts an item up (delta < 0) or down (delta > 0) in the queue.
+func (m *Manager) Move(id string, delta int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, it := range m.items {
+		if it.ID != id {
+			continue
+		}
+		j := i + delta
+		if j < 0 || j >= len(m.items) {
+			return nil
+		}
+		m.items[i], m.items[j] = m.items[j], m.items[i]
+		return m.saveLocked()
+	}
+	return nil
+}
+
+// SetPriority changes an item's priority, applying it immediately if the
+// item is downloading.
+func (m *Manager) SetPriority(id string, p Priority) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	it := m.findLocked(id)
+	if it == nil {
+		return nil
+	}
+	it.Priority = max(PriorityLow, min(PriorityHigh, p))
+	if t, ok := m.running[id]; ok {
+		applyPriority(selectFiles(t.files(), it.Batch), it.Priority)
+	}
+	return m.saveLocked()
+}
+
+// Close stops the background loop and persists the queue. Torrents are left
+// to the Client, which the caller closes separately.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	// Once closed, a tick still in flight can't start another activation
+	// after the ones below are cancelled.
+	m.closed = true
+	for _, cancel := range m.starting {
+		cancel()
+	}
+	m.mu.Unlock()
+	close(m.done)
+	m.wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saveLocked()
+}
+
+// run drives progress updates and scheduling until Close is called.
+func (m *Manager) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(queueTick)
+	defer ticker.Stop()
+
+	m.tick()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.tick()
+		}
+	}
+}
+
+// tick refreshes progress for running torrents, finalizes completed ones and
+// starts queued items while there are free slots.
+func (m *Manager) tick() {
+	m.mu.Lock()
+
+	var finished []QueueItem
+	changed := false
+	for id, t := range m.running {
+		it := m.findLocked(id)
+		if it == nil {
+			continue
+		}
+		files := selectFiles(t.files(), it.Batch)
+		prev := it.BytesCompleted
+		it.BytesCompleted, it.BytesTotal = fileBytes(files)
+		it.Speed = max(0, int64(float64(it.BytesCompleted-prev)/queueTick.Seconds()))
+
+		if it.BytesTotal > 0 && it.BytesCompleted >= it.BytesTotal {
+			it.State = StateCompleted
+			it.Speed = 0
+			it.Files = it.Files[:0]
+			for _, f := range files {
+				it.Files = append(it.Files, QueueFile{
+					Path: filepath.Join(m.client.DownloadDir(), filepath.FromSlash(f.Path())),
+					Size: f.Length(),
+				})
+			}
+			delete(m.running, id)
+			m.client.finish(t)
+			finished = append(finished, *it)
+			changed = true
+		}
+	}
+
+	if m.scheduleLocked() {
+		changed = true
+	}
+	if changed {
+		m.saveLocked()
+	}
+	m.mu.Unlock()
+
+	if m.opts.OnComplete == nil {
+		return
+	}
+	for _, done := range finished {
+		if err := m.opts.OnComplete(done); err != nil {
+			m.mu.Lock()
+			if it := m.findLocked(done.ID); it != nil {
+				it.Err = fmt.Sprintf("downloaded, but not recorded: %v", err)
+				m.saveLocked()
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+// scheduleLocked starts queued items by priority, then queue position, until
+// MaxActive downloads are running. Reports whether anything started.
+func (m *Manager) scheduleLocked() bool {
+	active := len(m.running) + len(m.starting)
+	if m.closed || active >= m.opts.MaxActive {
+		return false
+	}
+
+	order := make([]*QueueItem, len(m.items))
+	copy(order, m.items)
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Priority > order[j].Priority
+	})
+
+	started := false
+	for _, it := range order {
+		if active >= m.opts.MaxActive {
+			break
+		}
+		if it.State != StateQueued {
+			continue
+		}
+		m.startLocked(it)
+		active++
+		started = true
+	}
+	return started
+}
+
+// startLocked marks an item active and fetches its metadata in the background.
+func (m *Manager) startLocked(it *QueueItem) {
+	it.State = StateActive
+	it.Err = ""
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.starting[it.ID] = cancel
+
+	m.wg.Add(1)
+	go m.activate(ctx, it.ID, it.Source)
+}
+
+// activate adds the torrent, waits for its metadata, checks the disk quota
+// and begins downloading the selected files.
+func (m *Manager) activate(ctx context.Context, id, source string) {
+	defer m.wg.Done()
+
+	t, err := m.client.fetch(ctx, source)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cancel, ok := m.starting[id]; ok {
+		cancel()
+		delete(m.starting, id)
+	}
+
+	if m.closed {
+		// Left active so the download resumes on the next start.
+		if err == nil {
+			m.client.abandon(t)
+		}
+		return
+	}
+	it := m.findLocked(id)
+	if it == nil || it.State != StateActive {
+		// Paused or cancelled while waiting for metadata.
+		if err == nil {
+			m.client.abandon(t)
+		}
+		return
+	}
+	if err != nil {
+		it.State = StateFailed
+		it.Err = err.Error()
+		m.saveLocked()
+		return
+	}
+
+	files := selectFiles(t.files(), it.Batch)
+	_, size := fileBytes(files)
+	if m.opts.DiskQuota > 0 && m.usedLocked(id)+size > m.opts.DiskQuota {
+		m.client.abandon(t)
+		it.State = StatePaused
+		it.Err = fmt.Sprintf("needs %s, exceeds disk quota", FormatBytes(size))
+		m.saveLocked()
+		return
+	}
+
+	it.InfoHash = t.InfoHash().HexString()
+	it.BytesTotal = size
+	m.client.hold(t)
+	applyPriority(files, it.Priority)
+	m.running[id] = t
+	m.saveLocked()
+}
+
+// stopLocked halts an item's download without changing its state.
+func (m *Manager) stopLocked(it *QueueItem) {
+	if cancel, ok := m.starting[it.ID]; ok {
+		cancel()
+		delete(m.starting, it.ID)
+	}
+	if t, ok := m.running[it.ID]; ok {
+		m.releaseLocked(it.ID, t)
+	}
+}
+
+// releaseLocked forgets a running torrent, dropping it unless it is being
+// streamed.
+func (m *Manager) releaseLocked(id string, t queueTorrent) {
+	delete(m.running, id)
+	m.client.abandon(t)
+}
+
+// applyPriority requests the selected files at the piece priority matching p.
+func applyPriority(files []queueFile, p Priority) {
+	prio := torrent.PiecePriorityNormal
+	if p > PriorityNormal {
+		prio = torrent.PiecePriorityHigh
+	}
+	for _, f := range files {
+		f.SetPriority(prio)
+	}
+}
+
+// usedLocked sums the disk space taken by items other than id: the full size
+// of active ones, and the files of completed ones still on disk.
+func (m *Manager) usedLocked(id string) int64 {
+	var used int64
+	for _, it := range m.items {
+		if it.ID == id {
+			continue
+		}
+		switch it.State {
+		case StateActive:
+			used += it.BytesTotal
+		case StateCompleted:
+			for _, f := range it.Files {
+				if _, err := os.Stat(f.Path); err == nil {
+					used += f.Size
+				}
+			}
+		}
+	}
+	return used
+}
+
+// onDisk reports whether any of a completed item's files still exists.
+func (it *QueueItem) onDisk() bool {
+	for _, f := range it.Files {
+		if _, err := os.Stat(f.Path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) findLocked(id string) *QueueItem {
+	for _, it := range m.items {
+		if it.ID == id {
+			return it
+		}
+	}
+	return nil
+}
+
+// saveLocked writes the queue atomically. Callers must hold m.mu.
+func (m *Manager) saveLocked() error {
+	if m.path == "" {
+		return nil
+	}
+	if err := fsutil.WriteJSONAtomic(m.path, m.items); err != nil {
+		return fmt.Errorf("save download queue: %w", err)
+	}
+	return nil
+}
+
+// selectFiles returns the files a queue item downloads: every video file for
+// a batch (all files if none look like video), otherwise the largest file.
+func selectFiles(files []queueFile, batch bool) []queueFile {
+	if len(files) == 0 {
+		return nil
+	}
+
+	if batch {
+		var videos []queueFile
+		for _, f := range files {
+			if isVideoFile(f.Path()) {
+				videos = append(videos, f)
+			}
+		}
+		if len(videos) > 0 {
+			return videos
+		}
+		return files
+	}
+
+	largest := files[0]
+	for _, f := range files[1:] {
+		if f.Length() > largest.Length() {
+			largest = f
+		}
+	}
+	return []queueFile{largest}
+}
+
+// fileBytes returns the completed and total byte counts across files.
+func fileBytes(files []queueFile) (completed, total int64) {
+	for _, f := range files {
+		completed += f.BytesCompleted()
+		total += f.Length()
+	}
+	return completed, total
+}
+
+// isVideoFile reports whether the path has a common video extension.
+func isVideoFile(p string) bool {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".mkv", ".mp4", ".avi", ".webm", ".m4v", ".ts":
+		return true
+	}
+	return false
+}
+
+// queuedTorrent adapts a *torrent.Torrent to queueTorrent.
+type queuedTorrent struct {
+	*torrent.Torrent
+}
+
+func (t queuedTorrent) files() []queueFile {
+	files := t.Files()
+	out := make([]queueFile, len(files))
+	for i, f := range files {
+		out[i] = f
+	}
+	return out
+}
+
+func (c *Client) fetch(ctx context.Context, source string) (queueTorrent, error) {
+	t, err := c.addSource(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-t.GotInfo():
+		return queuedTorrent{t}, nil
+	case <-ctx.Done():
+		c.release(t)
+		return nil, fmt.Errorf("metadata wait cancelled: %w", ctx.Err())
+	}
+}
+
+func (c *Client) hold(t queueTorrent) {
+	c.pin(t.InfoHash())
+}
+
+func (c *Client) finish(t queueTorrent) {
+	c.unpin(t.InfoHash())
+	if tt := t.(queuedTorrent).Torrent; !c.isStreaming(tt) {
+		c.retire(tt)
+	}
+}
+
+func (c *Client) abandon(t queueTorrent) {
+	c.unpin(t.InfoHash())
+	c.release(t.(queuedTorrent).Torrent)
+}
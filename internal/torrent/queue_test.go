@@ -0,0 +1,362 @@
+package torrent
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// fakeClient serves torrents from memory. Sources it doesn't know wait for
+// metadata until their context is cancelled.
+type fakeClient struct {
+	dir string
+
+	mu       sync.Mutex
+	torrents map[string]*fakeTorrent // by source
+	held     map[metainfo.Hash]bool
+	dropped  []metainfo.Hash
+}
+
+func newFakeClient(dir string) *fakeClient {
+	return &fakeClient{
+		dir:      dir,
+		torrents: make(map[string]*fakeTorrent),
+		held:     make(map[metainfo.Hash]bool),
+	}
+}
+
+// add registers a single-file torrent for source.
+func (c *fakeClient) add(source string, length int64) *fakeFile {
+	f := &fakeFile{path: source + ".mkv", length: length}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.torrents[source] = &fakeTorrent{hash: sha1.Sum([]byte(source)), fs: []*fakeFile{f}}
+	return f
+}
+
+func (c *fakeClient) fetch(ctx context.Context, source string) (queueTorrent, error) {
+	c.mu.Lock()
+	t, ok := c.torrents[source]
+	c.mu.Unlock()
+	if !ok {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return t, nil
+}
+
+func (c *fakeClient) hold(t queueTorrent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.held[t.InfoHash()] = true
+}
+
+func (c *fakeClient) finish(t queueTorrent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.held, t.InfoHash())
+}
+
+func (c *fakeClient) abandon(t queueTorrent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.held, t.InfoHash())
+	c.dropped = append(c.dropped, t.InfoHash())
+}
+
+func (c *fakeClient) DownloadDir() string { return c.dir }
+
+type fakeTorrent struct {
+	hash metainfo.Hash
+	fs   []*fakeFile
+}
+
+func (t *fakeTorrent) InfoHash() metainfo.Hash { return t.hash }
+
+func (t *fakeTorrent) files() []queueFile {
+	out := make([]queueFile, len(t.fs))
+	for i, f := range t.fs {
+		out[i] = f
+	}
+	return out
+}
+
+type fakeFile struct {
+	mu        sync.Mutex
+	path      string
+	length    int64
+	completed int64
+	prio      torrent.PiecePriority
+}
+
+func (f *fakeFile) Path() string  { return f.path }
+func (f *fakeFile) Length() int64 { return f.length }
+
+func (f *fakeFile) BytesCompleted() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.completed
+}
+
+func (f *fakeFile) SetPriority(p torrent.PiecePriority) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prio = p
+}
+
+func (f *fakeFile) complete() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.completed = f.length
+}
+
+// step runs one scheduling pass and waits for the activations it started.
+func step(m *Manager) {
+	m.tick()
+	m.wg.Wait()
+}
+
+// states returns each item's state by title.
+func states(m *Manager) map[string]ItemState {
+	out := make(map[string]ItemState)
+	for _, it := range m.Items() {
+		out[it.Title] = it.State
+	}
+	return out
+}
+
+func enqueue(t *testing.T, m *Manager, sources ...string) {
+	t.Helper()
+	for _, s := range sources {
+		if err := m.Enqueue(QueueItem{Source: s, Title: s}); err != nil {
+			t.Fatalf("Enqueue(%s): %v", s, err)
+		}
+	}
+}
+
+func TestManager_ReloadRequeuesActiveItems(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "queue.json")
+	data := `[
+  {"id": "a", "source": "a", "title": "a", "state": "downloading", "bytes_total": 100},
+  {"id": "b", "source": "b", "title": "b", "state": "paused"},
+  {"id": "c", "source": "c", "title": "c", "state": "completed"}
+]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := newManager(newFakeClient(t.TempDir()), path, ManagerOptions{})
+	if err != nil {
+		t.Fatalf("newManager: %v", err)
+	}
+	got := states(m)
+	want := map[string]ItemState{"a": StateQueued, "b": StatePaused, "c": StateCompleted}
+	for title, state := range want {
+		if got[title] != state {
+			t.Errorf("%s: state %q, want %q", title, got[title], state)
+		}
+	}
+}
+
+func TestManager_MaxActive(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t.TempDir())
+	first := client.add("a", 100)
+	client.add("b", 100)
+	client.add("c", 100)
+
+	m, err := newManager(client, "", ManagerOptions{MaxActive: 2})
+	if err != nil {
+		t.Fatalf("newManager: %v", err)
+	}
+	enqueue(t, m, "a", "b", "c")
+
+	step(m)
+	step(m)
+	if got := states(m); got["a"] != StateActive || got["b"] != StateActive || got["c"] != StateQueued {
+		t.Fatalf("states = %v, want a and b downloading, c queued", got)
+	}
+
+	first.complete()
+	step(m)
+	if got := states(m); got["a"] != StateCompleted || got["c"] != StateActive {
+		t.Fatalf("states = %v, want a completed and c started", got)
+	}
+}
+
+func TestManager_CompletionErrorKeptOnItem(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t.TempDir())
+	file := client.add("a", 100)
+	m, err := newManager(client, "", ManagerOptions{
+		OnComplete: func(QueueItem) error { return errors.New("disk full") },
+	})
+	if err != nil {
+		t.Fatalf("newManager: %v", err)
+	}
+	enqueue(t, m, "a")
+	step(m)
+	file.complete()
+	step(m)
+
+	it := m.Items()[0]
+	if it.State != StateCompleted || !strings.Contains(it.Err, "disk full") {
+		t.Fatalf("item = %q, %q; want completed with the recording error", it.State, it.Err)
+	}
+}
+
+func TestManager_RequeueDeletedDownload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	client := newFakeClient(dir)
+	file := client.add("a", 100)
+	m, err := newManager(client, "", ManagerOptions{})
+	if err != nil {
+		t.Fatalf("newManager: %v", err)
+	}
+	enqueue(t, m, "a")
+	step(m)
+	path := filepath.Join(dir, file.path)
+	if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file.complete()
+	step(m)
+
+	if err := m.Enqueue(QueueItem{Source: "a", Title: "a"}); !errors.Is(err, ErrAlreadyQueued) {
+		t.Fatalf("Enqueue with the file on disk = %v, want ErrAlreadyQueued", err)
+	}
+
+	// Deleted from the downloads view: queueing it again starts over.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Enqueue(QueueItem{Source: "a", Title: "a"}); err != nil {
+		t.Fatalf("Enqueue after deleting the file: %v", err)
+	}
+	items := m.Items()
+	if len(items) != 1 || items[0].State != StateQueued {
+		t.Fatalf("items = %+v, want just the new queued download", items)
+	}
+}
+
+func TestManager_DiskQuota(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	client := newFakeClient(dir)
+	first := client.add("a", 60)
+	client.add("b", 60)
+
+	m, err := newManager(client, "", ManagerOptions{MaxActive: 1, DiskQuota: 100})
+	if err != nil {
+		t.Fatalf("newManager: %v", err)
+	}
+	enqueue(t, m, "a", "b")
+
+	step(m)
+	// a's file is on disk when it completes, leaving no room for b.
+	if err := os.WriteFile(filepath.Join(dir, first.path), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	first.complete()
+	step(m)
+	if got := states(m); got["a"] != StateCompleted || got["b"] != StatePaused {
+		t.Fatalf("states = %v, want a completed and b paused over quota", got)
+	}
+	if len(client.dropped) != 1 {
+		t.Fatalf("dropped %d torrents, want the one over quota", len(client.dropped))
+	}
+
+	// Completed files stop counting once they are deleted.
+	if err := os.Remove(filepath.Join(dir, first.path)); err != nil {
+		t.Fatal(err)
+	}
+	m.TogglePause(m.Items()[1].ID)
+	step(m)
+	if got := states(m); got["b"] != StateActive {
+		t.Fatalf("b = %q after a's file was deleted, want downloading", got["b"])
+	}
+}
+
+func TestManager_StartOrder(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t.TempDir())
+	files := map[string]*fakeFile{}
+	for _, s := range []string{"a", "b", "c", "d"} {
+		files[s] = client.add(s, 100)
+	}
+
+	m, err := newManager(client, "", ManagerOptions{MaxActive: 1})
+	if err != nil {
+		t.Fatalf("newManager: %v", err)
+	}
+	enqueue(t, m, "a", "b", "c", "d")
+	ids := map[string]string{}
+	for _, it := range m.Items() {
+		ids[it.Title] = it.ID
+	}
+
+	// c moves to the front; d is high priority and goes ahead of it.
+	m.Move(ids["c"], -1)
+	m.Move(ids["c"], -1)
+	m.SetPriority(ids["d"], PriorityHigh)
+
+	var order []string
+	for range 4 {
+		step(m)
+		for _, it := range m.Items() {
+			if it.State == StateActive {
+				order = append(order, it.Title)
+				files[it.Title].complete()
+			}
+		}
+	}
+	want := []string{"d", "c", "a", "b"}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("start order = %v, want %v", order, want)
+		}
+	}
+	if files["d"].prio != torrent.PiecePriorityHigh {
+		t.Fatalf("high priority item requested at %v", files["d"].prio)
+	}
+}
+
+func TestManager_CloseCancelsMetadataWait(t *testing.T) {
+	t.Parallel()
+
+	// Nothing is registered, so activations wait for metadata forever.
+	m, err := newManager(newFakeClient(t.TempDir()), "", ManagerOptions{})
+	if err != nil {
+		t.Fatalf("newManager: %v", err)
+	}
+	enqueue(t, m, "a", "b")
+	m.tick()
+	m.Close()
+
+	m.tick() // a tick after Close must not start anything
+	if n := len(m.starting); n != 0 {
+		t.Fatalf("%d activations started after Close", n)
+	}
+	// Interrupted downloads resume on the next start instead of failing.
+	for title, state := range states(m) {
+		if state != StateActive {
+			t.Errorf("%s: state %q after Close, want downloading", title, state)
+		}
+	}
+}
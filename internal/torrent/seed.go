@@ -0,0 +1,118 @@
+package torrent
+
+import (
+	"time"
+
+	"github.com/anacrolix/torrent"
+)
+
+// seedCheckInterval is how often seeding torrents are checked against the
+// seed policy.
+const seedCheckInterval = 30 * time.Second
+
+// SeedPolicy controls whether finished torrents keep uploading and for how
+// long.
+type SeedPolicy struct {
+	Enabled bool
+	// Ratio stops seeding once uploaded bytes reach Ratio times the torrent
+	// size; 0 means no ratio target.
+	Ratio float64
+	// Time stops seeding after this long; 0 means no time limit.
+	Time time.Duration
+}
+
+// seedingTorrent is a finished torrent kept open to upload.
+type seedingTorrent struct {
+	t       *torrent.Torrent
+	started time.Time
+}
+
+// done reports whether the policy's ratio or time limit has been reached by
+// a torrent of size bytes that has uploaded uploaded bytes since started.
+// With neither limit set, seeding continues until the client closes.
+func (p SeedPolicy) done(uploaded, size int64, started, now time.Time) bool {
+	if p.Time > 0 && now.Sub(started) >= p.Time {
+		return true
+	}
+	return p.Ratio > 0 && size > 0 && float64(uploaded)/float64(size) >= p.Ratio
+}
+
+// seedTotals returns how many bytes t has uploaded and its size, which is 0
+// until its metadata arrives.
+func seedTotals(t *torrent.Torrent) (uploaded, size int64) {
+	if t.Info() == nil {
+		return 0, 0
+	}
+	stats := t.Stats()
+	return stats.BytesWrittenData.Int64(), t.Length()
+}
+
+// retire hands a finished torrent over to the seed policy, or drops it when
+// seeding is disabled.
+func (c *Client) retire(t *torrent.Torrent) {
+	if !c.opts.Seed.Enabled {
+		t.Drop()
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.seeding[t.InfoHash()]; !ok {
+		c.seeding[t.InfoHash()] = seedingTorrent{t: t, started: time.Now()}
+	}
+}
+
+// drop removes a torrent from the client and from the seeding set.
+func (c *Client) drop(t *torrent.Torrent) {
+	c.mu.Lock()
+	delete(c.seeding, t.InfoHash())
+	c.mu.Unlock()
+	t.Drop()
+}
+
+// Seeding returns the number of finished torrents still uploading.
+func (c *Client) Seeding() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.seeding)
+}
+
+// runSeedPolicy periodically stops torrents that met their seed target.
+func (c *Client) runSeedPolicy() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(seedCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-ticker.C:
+			c.enforceSeedPolicy(now)
+		}
+	}
+}
+
+// enforceSeedPolicy drops seeding torrents that reached the ratio or time
+// limit. Torrents picked up again by the queue or a stream are left alone.
+func (c *Client) enforceSeedPolicy(now time.Time) {
+	c.mu.Lock()
+	var expired []*torrent.Torrent
+	for h, s := range c.seeding {
+		if c.pinned[h] {
+			delete(c.seeding, h)
+			continue
+		}
+		uploaded, size := seedTotals(s.t)
+		if c.opts.Seed.done(uploaded, size, s.started, now) {
+			delete(c.seeding, h)
+			expired = append(expired, s.t)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, t := range expired {
+		if !c.isStreaming(t) {
+			t.Drop()
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package torrent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeedPolicy_Done(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		policy   SeedPolicy
+		uploaded int64
+		size     int64
+		elapsed  time.Duration
+		want     bool
+	}{
+		{"ratio only, below", SeedPolicy{Enabled: true, Ratio: 2}, 150, 100, 48 * time.Hour, false},
+		{"ratio only, reached", SeedPolicy{Enabled: true, Ratio: 2}, 200, 100, time.Minute, true},
+		{"ratio only, no metadata", SeedPolicy{Enabled: true, Ratio: 2}, 0, 0, time.Minute, false},
+		{"time only, before", SeedPolicy{Enabled: true, Time: time.Hour}, 1000, 100, 59 * time.Minute, false},
+		{"time only, reached", SeedPolicy{Enabled: true, Time: time.Hour}, 0, 100, time.Hour, true},
+		{"both, ratio first", SeedPolicy{Enabled: true, Ratio: 1, Time: time.Hour}, 100, 100, time.Minute, true},
+		{"both, time first", SeedPolicy{Enabled: true, Ratio: 1, Time: time.Hour}, 10, 100, 2 * time.Hour, true},
+		{"both, neither reached", SeedPolicy{Enabled: true, Ratio: 1, Time: time.Hour}, 10, 100, time.Minute, false},
+		{"neither", SeedPolicy{Enabled: true}, 1 << 40, 100, 365 * 24 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.done(tt.uploaded, tt.size, started, started.Add(tt.elapsed)); got != tt.want {
+			t.Errorf("%s: done = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package torrent
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SourceKind identifies how a playback source should be opened.
+type SourceKind int
+
+const (
+	// SourceMagnet is a magnet URI.
+	SourceMagnet SourceKind = iota
+	// SourceTorrentURL is an http(s) URL pointing at a .torrent file.
+	SourceTorrentURL
+	// SourceTorrentFile is a path to a .torrent file on disk.
+	SourceTorrentFile
+	// SourceLocalFile is a plain video file on disk; it bypasses the torrent client.
+	SourceLocalFile
+)
+
+// ClassifySource reports which kind of playback source s refers to.
+func ClassifySource(s string) SourceKind {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case strings.HasPrefix(lower, "magnet:"):
+		return SourceMagnet
+	case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"):
+		return SourceTorrentURL
+	case strings.HasSuffix(lower, ".torrent"):
+		return SourceTorrentFile
+	default:
+		return SourceLocalFile
+	}
+}
+
+// NormalizeSource trims whitespace and surrounding quotes (as left by
+// terminal drag-and-drop), turns a file:// URL into a path and expands a
+// leading "~/" to the home directory.
+func NormalizeSource(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	if strings.HasPrefix(strings.ToLower(s), "file://") {
+		if u, err := url.Parse(s); err == nil && u.Path != "" {
+			s = u.Path
+		}
+	}
+	if strings.HasPrefix(s, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			s = filepath.Join(home, s[2:])
+		}
+	}
+	return s
+}
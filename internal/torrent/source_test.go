@@ -0,0 +1,62 @@
+package torrent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifySource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source string
+		want   SourceKind
+	}{
+		{"magnet:?xt=urn:btih:abc123", SourceMagnet},
+		{"  MAGNET:?xt=urn:btih:abc123", SourceMagnet},
+		{"http://example.com/file.torrent", SourceTorrentURL},
+		{"https://nyaa.si/download/123.torrent", SourceTorrentURL},
+		{"/home/me/Downloads/show.torrent", SourceTorrentFile},
+		{"show.TORRENT", SourceTorrentFile},
+		{"/home/me/Videos/show - 01.mkv", SourceLocalFile},
+		{"file:///home/me/Videos/show.mkv", SourceLocalFile},
+		{"not a source at all", SourceLocalFile},
+		{"", SourceLocalFile},
+	}
+	for _, tt := range tests {
+		if got := ClassifySource(tt.source); got != tt.want {
+			t.Errorf("ClassifySource(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSource(t *testing.T) {
+	t.Parallel()
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"magnet:?xt=urn:btih:abc123", "magnet:?xt=urn:btih:abc123"},
+		{"  https://nyaa.si/download/123.torrent\n", "https://nyaa.si/download/123.torrent"},
+		{"'/home/me/Downloads/show.torrent'", "/home/me/Downloads/show.torrent"},
+		{`"/home/me/Videos/show - 01.mkv" `, "/home/me/Videos/show - 01.mkv"},
+		{"~/Videos/show.mkv", filepath.Join(home, "Videos/show.mkv")},
+		{"file:///home/me/Videos/show%20-%2001.mkv", "/home/me/Videos/show - 01.mkv"},
+		{"'file:///home/me/show.torrent'", "/home/me/show.torrent"},
+		{`"unbalanced`, `"unbalanced`},
+		{"not a source at all", "not a source at all"},
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeSource(tt.source); got != tt.want {
+			t.Errorf("NormalizeSource(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 type Stats struct {
 	BytesCompleted int64
 	BytesTotal     int64
+	Downloaded     int64 // piece data received from peers since the torrent was added
 	Peers          int
 	Seeders        int
 }
@@ -28,6 +29,7 @@ func GetStats(t *torrent.Torrent) Stats {
 	return Stats{
 		BytesCompleted: completed,
 		BytesTotal:     total,
+		Downloaded:     stats.BytesReadUsefulData.Int64(),
 		Peers:          stats.ActivePeers,
 		Seeders:        stats.ConnectedSeeders,
 	}
@@ -3,65 +3,228 @@ package torrent
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"path/filepath"
 
 	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
 )
 
 const readaheadBytes = 10 * 1024 * 1024 // 10 MB
 
-// AddMagnetAndStream adds a magnet URI, waits for metadata, and returns a
-// Reader for the largest file in the torrent along with its filename.
-func (c *Client) AddMagnetAndStream(ctx context.Context, magnetURI string) (torrent.Reader, string, error) {
-	if c.activeTor != nil {
-		c.activeTor.Drop()
-		c.activeTor = nil
-	}
+// AddSourceAndStream adds a magnet URI, .torrent URL or .torrent file path,
+// waits for metadata, and returns a Reader for the largest file in the
+// torrent along with its filename. Local video files are not handled here;
+// callers should hand them to the player directly.
+func (c *Client) AddSourceAndStream(ctx context.Context, source string) (torrent.Reader, string, error) {
+	c.StopStream()
 
-	t, err := c.client.AddMagnet(magnetURI)
+	t, err := c.addSource(ctx, source)
 	if err != nil {
-		return nil, "", fmt.Errorf("add magnet: %w", err)
+		return nil, "", err
 	}
+	c.mu.Lock()
 	c.activeTor = t
+	c.mu.Unlock()
+	c.takePrefetch(t)
 
 	// Wait for torrent metadata (piece info, file list).
 	select {
 	case <-t.GotInfo():
 	case <-ctx.Done():
-		t.Drop()
-		c.activeTor = nil
+		c.StopStream()
 		return nil, "", fmt.Errorf("metadata wait cancelled: %w", ctx.Err())
 	}
 
 	// Find the largest file (the video).
-	files := t.Files()
-	if len(files) == 0 {
-		t.Drop()
-		c.activeTor = nil
+	largest := largestFile(t)
+	if largest == nil {
+		c.StopStream()
 		return nil, "", fmt.Errorf("torrent has no files")
 	}
 
-	largest := files[0]
-	for _, f := range files[1:] {
-		if f.Length() > largest.Length() {
+	// Deprioritize all other files, unless the queue is downloading them.
+	if !c.isPinned(t.InfoHash()) {
+		deprioritizeOthers(t, largest)
+	}
+
+	c.mu.Lock()
+	c.activeFile = largest
+	c.mu.Unlock()
+
+	reader := largest.NewReader()
+	reader.SetReadahead(readaheadBytes)
+	reader.SetResponsive()
+
+	return reader, largest.DisplayPath(), nil
+}
+
+// Prefetch adds source alongside the current stream and downloads the first n
+// bytes of its largest file, so a later AddSourceAndStream of the same source
+// starts without waiting for metadata or buffering. Only the most recent
+// prefetch is kept; an older one is dropped.
+func (c *Client) Prefetch(ctx context.Context, source string, n int64) error {
+	t, err := c.addSource(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-t.GotInfo():
+	case <-ctx.Done():
+		c.release(t)
+		return fmt.Errorf("metadata wait cancelled: %w", ctx.Err())
+	}
+
+	largest := largestFile(t)
+	if largest == nil {
+		c.release(t)
+		return fmt.Errorf("torrent has no files")
+	}
+
+	c.mu.Lock()
+	old := c.prefetched
+	c.prefetched = t
+	c.mu.Unlock()
+	if old != nil && old != t {
+		c.release(old)
+	}
+
+	// A torrent that is also streaming or queued keeps its file priorities.
+	if !c.isPinned(t.InfoHash()) && !c.isStreaming(t) {
+		deprioritizeOthers(t, largest)
+	}
+
+	pieceLen := t.Info().PieceLength
+	begin := largest.BeginPieceIndex()
+	end := min(largest.EndPieceIndex(), begin+int((n+pieceLen-1)/pieceLen))
+	t.DownloadPieces(begin, end)
+	return nil
+}
+
+// takePrefetch hands a prefetched torrent over to streaming. A prefetch for a
+// different torrent is no longer needed and is dropped.
+func (c *Client) takePrefetch(t *torrent.Torrent) {
+	c.mu.Lock()
+	old := c.prefetched
+	c.prefetched = nil
+	c.mu.Unlock()
+	if old != nil && old != t {
+		c.release(old)
+	}
+}
+
+// release drops t unless it is streaming, queued or prefetched.
+func (c *Client) release(t *torrent.Torrent) {
+	c.mu.Lock()
+	held := c.pinned[t.InfoHash()] || c.prefetched == t
+	c.mu.Unlock()
+	if !held && !c.isStreaming(t) {
+		c.drop(t)
+	}
+}
+
+// largestFile returns the biggest file in t (the video), or nil.
+func largestFile(t *torrent.Torrent) *torrent.File {
+	var largest *torrent.File
+	for _, f := range t.Files() {
+		if largest == nil || f.Length() > largest.Length() {
 			largest = f
 		}
 	}
+	return largest
+}
 
-	// Deprioritize all other files.
-	for _, f := range files {
-		if f != largest {
+// deprioritizeOthers stops downloading every file in t except keep.
+func deprioritizeOthers(t *torrent.Torrent, keep *torrent.File) {
+	for _, f := range t.Files() {
+		if f != keep {
 			f.SetPriority(torrent.PiecePriorityNone)
 		}
 	}
+}
 
-	reader := largest.NewReader()
-	reader.SetReadahead(readaheadBytes)
-	reader.SetResponsive()
+// addSource adds the torrent described by source to the client.
+func (c *Client) addSource(ctx context.Context, source string) (*torrent.Torrent, error) {
+	switch ClassifySource(source) {
+	case SourceMagnet:
+		t, err := c.client.AddMagnet(source)
+		if err != nil {
+			return nil, fmt.Errorf("add magnet: %w", err)
+		}
+		return t, nil
 
-	return reader, largest.DisplayPath(), nil
+	case SourceTorrentURL:
+		mi, err := fetchMetaInfo(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		t, err := c.client.AddTorrent(mi)
+		if err != nil {
+			return nil, fmt.Errorf("add torrent: %w", err)
+		}
+		return t, nil
+
+	case SourceTorrentFile:
+		mi, err := metainfo.LoadFromFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("load torrent file: %w", err)
+		}
+		t, err := c.client.AddTorrent(mi)
+		if err != nil {
+			return nil, fmt.Errorf("add torrent: %w", err)
+		}
+		return t, nil
+
+	default:
+		return nil, fmt.Errorf("not a torrent source: %s", source)
+	}
+}
+
+// fetchMetaInfo downloads and decodes a .torrent file over http(s).
+func fetchMetaInfo(ctx context.Context, rawURL string) (*metainfo.MetaInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/x-bittorrent")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download torrent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download torrent: status %d", resp.StatusCode)
+	}
+
+	mi, err := metainfo.Load(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decode torrent: %w", err)
+	}
+	return mi, nil
 }
 
 // ActiveTorrent returns the currently active torrent, or nil.
 func (c *Client) ActiveTorrent() *torrent.Torrent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.activeTor
 }
+
+// ActiveFile returns the file being streamed from the active torrent, or nil.
+func (c *Client) ActiveFile() *torrent.File {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.activeFile
+}
+
+// ActiveFilePath returns the on-disk path of the file being streamed, or "".
+func (c *Client) ActiveFilePath() string {
+	f := c.ActiveFile()
+	if f == nil {
+		return ""
+	}
+	return filepath.Join(c.downloadDir, filepath.FromSlash(f.Path()))
+}
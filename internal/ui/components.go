@@ -1,22 +1,30 @@
 package ui
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
 const appTitle = " ani-tui "
 
-// RenderHeader returns a full-width header bar with the app title.
-func RenderHeader(width int) string {
+// RenderHeader returns a full-width header bar with the app title and any
+// badges right-aligned.
+func RenderHeader(width int, badges ...string) string {
 	title := HeaderStyle.Render(appTitle)
-	gap := width - lipgloss.Width(title)
+	var right string
+	for _, b := range badges {
+		right += BadgeStyle.Render(b)
+	}
+	gap := width - lipgloss.Width(title) - lipgloss.Width(right)
 	if gap < 0 {
 		gap = 0
 	}
 	fill := lipgloss.NewStyle().
 		Background(ColorHeaderBg).
 		Render(repeatChar(' ', gap))
-	return title + fill
+	return title + fill + right
 }
 
 // RenderStatusBar returns a full-width status bar with the given text.
@@ -29,6 +37,61 @@ func RenderError(msg string) string {
 	return ErrorStyle.Render("Error: " + msg)
 }
 
+// Bar is one row of a bar chart.
+type Bar struct {
+	Label string
+	Value int
+}
+
+// RenderBarChart draws bars as horizontal rows of "█" scaled to the largest
+// value, with labels on the left and values on the right, in width cells.
+func RenderBarChart(bars []Bar, width int) string {
+	labelWidth, valueWidth, top := 0, 0, 0
+	for _, b := range bars {
+		labelWidth = max(labelWidth, lipgloss.Width(b.Label))
+		valueWidth = max(valueWidth, len(fmt.Sprint(b.Value)))
+		top = max(top, b.Value)
+	}
+	barWidth := max(1, width-labelWidth-valueWidth-2)
+
+	label := lipgloss.NewStyle().Width(labelWidth).Foreground(ColorSubtle)
+	bar := lipgloss.NewStyle().Foreground(ColorPrimary)
+	lines := make([]string, len(bars))
+	for i, b := range bars {
+		n := 0
+		if top > 0 {
+			n = b.Value * barWidth / top
+		}
+		if n == 0 && b.Value > 0 {
+			n = 1 // keep small values visible
+		}
+		lines[i] = label.Render(b.Label) + " " + bar.Render(strings.Repeat("█", n)) +
+			strings.Repeat(" ", barWidth-n) + " " + fmt.Sprintf("%*d", valueWidth, b.Value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sparkLevels are the block heights a sparkline is drawn with.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// RenderSparkline draws one block per value, scaled to the largest. Zero
+// values are left blank so gaps stand out.
+func RenderSparkline(values []int) string {
+	top := 0
+	for _, v := range values {
+		top = max(top, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		if v <= 0 || top == 0 {
+			b.WriteByte(' ')
+			continue
+		}
+		b.WriteRune(sparkLevels[(v*(len(sparkLevels)-1)+top-1)/top])
+	}
+	return lipgloss.NewStyle().Foreground(ColorPrimary).Render(b.String())
+}
+
 func repeatChar(c byte, n int) string {
 	if n <= 0 {
 		return ""
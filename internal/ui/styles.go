@@ -26,6 +26,13 @@ var HeaderStyle = lipgloss.NewStyle().
 	Background(ColorHeaderBg).
 	Padding(0, 1)
 
+// BadgeStyle marks app-wide states, such as offline mode, in the header.
+var BadgeStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color("#FFFFFF")).
+	Background(ColorError).
+	Padding(0, 1)
+
 // StatusBar renders the bottom status bar.
 var StatusBarStyle = lipgloss.NewStyle().
 	Foreground(ColorSubtle).
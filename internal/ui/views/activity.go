@@ -0,0 +1,225 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rayanxn/ani-tui/internal/anilist"
+	"github.com/rayanxn/ani-tui/internal/ui"
+)
+
+// activityFetchedMsg carries a page of followed users' activity. A stale
+// first page comes from the cache and is being refreshed.
+type activityFetchedMsg struct {
+	page       int
+	activities []anilist.ListActivity
+	more       bool
+	err        error
+	stale      bool
+	refresh    bool // fetched to replace activity already shown
+}
+
+// ActivityListItem wraps an anilist.ListActivity for bubbles/list rendering.
+type ActivityListItem struct {
+	activity anilist.ListActivity
+}
+
+func (i ActivityListItem) Title() string       { return i.activity.Media.Title.DisplayTitle() }
+func (i ActivityListItem) FilterValue() string { return i.activity.Media.Title.DisplayTitle() }
+func (i ActivityListItem) Description() string {
+	a := i.activity
+	text := a.User.Name + " " + a.Status
+	if a.Progress != "" {
+		text += " " + a.Progress
+	}
+	age := int(time.Since(time.Unix(a.CreatedAt, 0)).Seconds())
+	return fmt.Sprintf("%s · %s ago", text, formatTimeUntil(age))
+}
+
+// ActivityModel lists recent anime list updates from the users the viewer
+// follows.
+type ActivityModel struct {
+	client      *anilist.Client
+	list        list.Model
+	spinner     spinner.Model
+	page        int // last page loaded
+	more        bool
+	loading     bool
+	loadingMore bool
+	err         error
+}
+
+// NewActivityModel creates an activity feed view.
+func NewActivityModel(client *anilist.Client) ActivityModel {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(ui.ColorPrimary).
+		BorderLeftForeground(ui.ColorPrimary)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(ui.ColorSecondary).
+		BorderLeftForeground(ui.ColorPrimary)
+
+	l := list.New(nil, delegate, 0, 0)
+	l.Title = "Activity"
+	l.SetShowHelp(false)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.Styles.Title = ui.TitleStyle
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.SpinnerStyle
+
+	return ActivityModel{
+		client:  client,
+		list:    l,
+		spinner: s,
+		loading: true,
+	}
+}
+
+func (m ActivityModel) Init() tea.Cmd {
+	return tea.Batch(
+		m.spinner.Tick,
+		fetchActivityCmd(m.client, 1, false),
+	)
+}
+
+func (m ActivityModel) Update(msg tea.Msg) (ActivityModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height-6)
+		return m, nil
+
+	case activityFetchedMsg:
+		if msg.page == 1 {
+			m.loading = false
+		} else {
+			m.loadingMore = false
+		}
+		if msg.err != nil {
+			// A failed refresh or next page keeps what is on screen.
+			if len(m.list.Items()) == 0 || (msg.page == 1 && !msg.refresh) {
+				m.err = msg.err
+			}
+			return m, nil
+		}
+		m.err = nil
+		items := make([]list.Item, len(msg.activities))
+		for i, a := range msg.activities {
+			items[i] = ActivityListItem{activity: a}
+		}
+		if msg.page == 1 {
+			cursor := m.list.Index()
+			m.list.SetItems(items)
+			m.list.Select(min(cursor, max(len(items)-1, 0)))
+		} else if msg.page == m.page+1 {
+			m.list.SetItems(append(m.list.Items(), items...))
+		} else {
+			return m, nil // a page from before a refresh
+		}
+		m.page = msg.page
+		m.more = msg.more
+		if msg.stale {
+			return m, fetchActivityCmd(m.client, 1, true)
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.loading || m.loadingMore {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.err != nil {
+			if msg.String() == "esc" || msg.String() == "enter" {
+				m.err = nil
+				return m, nil
+			}
+		}
+
+		switch msg.String() {
+		case "ctrl+r":
+			m.loading = true
+			m.loadingMore = false
+			m.err = nil
+			return m, tea.Batch(m.spinner.Tick, fetchActivityCmd(m.client, 1, true))
+		case "n":
+			if !m.more || m.loading || m.loadingMore {
+				return m, nil
+			}
+			m.loadingMore = true
+			return m, tea.Batch(m.spinner.Tick, fetchActivityCmd(m.client, m.page+1, false))
+		case "enter":
+			item, ok := m.list.SelectedItem().(ActivityListItem)
+			if !ok {
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return NavigateToDetailMsg{AnimeID: item.activity.Media.ID}
+			}
+		}
+
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// View renders the activity feed within the given dimensions.
+func (m ActivityModel) View(width, height int) string {
+	var footer string
+	switch {
+	case m.loadingMore:
+		footer = ui.HelpStyle.Render("  " + m.spinner.View() + " Loading more...")
+	case m.more:
+		footer = ui.HelpStyle.Render("  n to load more")
+	}
+
+	listHeight := height - lipgloss.Height(footer)
+	if listHeight < 0 {
+		listHeight = 0
+	}
+	m.list.SetSize(width, listHeight)
+
+	var body string
+	switch {
+	case m.loading:
+		body = lipgloss.NewStyle().Padding(1, 2).Render(m.spinner.View() + " Loading activity...")
+	case m.err != nil:
+		body = lipgloss.NewStyle().Padding(1, 0).Render(ui.RenderError(m.err.Error()))
+	case len(m.list.Items()) == 0:
+		body = ui.HelpStyle.Render("  No recent activity from people you follow")
+	default:
+		body = m.list.View() + "\n" + footer
+	}
+
+	return lipgloss.NewStyle().Width(width).Height(height).Render(body)
+}
+
+// fetchActivityCmd fetches a page of activity. A cached first page is
+// returned first unless refresh is set.
+func fetchActivityCmd(client *anilist.Client, page int, refresh bool) tea.Cmd {
+	return func() tea.Msg {
+		if !refresh && page == 1 {
+			if activities, more, fresh, ok := client.CachedActivity(page); ok {
+				return activityFetchedMsg{page: page, activities: activities, more: more, stale: !fresh}
+			}
+		}
+		activities, more, err := client.GetActivity(context.Background(), page)
+		return activityFetchedMsg{page: page, activities: activities, more: more, err: err, refresh: refresh}
+	}
+}
@@ -2,15 +2,24 @@ package views
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/rayanxn/ani-tui/internal/anilist"
+	"github.com/rayanxn/ani-tui/internal/cache"
 	"github.com/rayanxn/ani-tui/internal/config"
+	"github.com/rayanxn/ani-tui/internal/downloads"
+	"github.com/rayanxn/ani-tui/internal/history"
 	"github.com/rayanxn/ani-tui/internal/nyaa"
+	"github.com/rayanxn/ani-tui/internal/termimg"
+	"github.com/rayanxn/ani-tui/internal/torrent"
 	"github.com/rayanxn/ani-tui/internal/ui"
 )
 
@@ -24,60 +33,159 @@ const (
 	ViewPlayer
 	ViewLibrary
 	ViewAuth
+	ViewDownloads
+	ViewQueue
+	ViewActivity
+	ViewStats
 )
 
+// Services bundles long-lived resources shared across views.
+type Services struct {
+	Downloads  *downloads.Index
+	Torrents   *torrent.Client     // nil if the client failed to start
+	Queue      *torrent.Manager    // nil if the client or queue failed to start
+	TorrentErr error               // why Torrents or Queue is nil
+	Cache      *cache.Cache        // AniList responses; may be nil
+	Outbox     *anilist.Outbox     // list updates made while offline; may be nil
+	History    *history.Store      // playback positions; may be nil
+	Sessions   *history.SessionLog // watch sessions for the stats view; may be nil
+	Graphics   termimg.Protocol    // how cover art is drawn; termimg.None hides it
+}
+
 // Navigation messages emitted by sub-views.
 type (
 	NavigateToDetailMsg   struct{ AnimeID int }
 	NavigateToTorrentsMsg struct {
 		AnimeID int
 		Request nyaa.SearchRequest
+		// AutoPick plays the result matching Release's group and
+		// resolution without showing the list, falling back to the list
+		// when nothing matches.
+		AutoPick bool
+		Release  string // optional; empty picks by ranking profile alone
+		// LastEpisode is the last episode that has aired, or 0 if unknown.
+		LastEpisode int
 	}
 	NavigateToPlayerMsg struct {
-		MagnetURI  string
+		// Source is a magnet URI, an http(s) .torrent URL, a .torrent file
+		// path, or a local video file path.
+		Source     string
 		AnimeID    int
 		AnimeTitle string
 		Episode    int
+		// Request and Release describe the search and the chosen release
+		// title; the player uses them to prefetch the next episode. Both
+		// may be empty.
+		Request nyaa.SearchRequest
+		Release string
+		// LastEpisode is the last episode that has aired, or 0 if unknown;
+		// nothing past it is prefetched.
+		LastEpisode int
 	}
-	NavigateToLibraryMsg struct{}
-	NavigateBackMsg      struct{}
+	NavigateToLibraryMsg   struct{}
+	NavigateToDownloadsMsg struct{}
+	NavigateToActivityMsg  struct{}
+	NavigateToStatsMsg     struct{}
+	NavigateBackMsg        struct{}
 )
 
 type updateProgressMsg struct {
-	err error
+	animeID int
+	episode int
+	err     error
+}
+
+// connectivityMsg reports whether AniList was reachable at the last check.
+type connectivityMsg struct{ online bool }
+
+// connectivityTickMsg schedules the next connectivity check.
+type connectivityTickMsg struct{}
+
+// outboxFlushedMsg reports queued offline updates sent to AniList.
+type outboxFlushedMsg struct {
+	sent int
+	err  error
 }
 
+// autoPickedMsg carries the outcome of an auto-pick search: the
+// NavigateToPlayerMsg to play, or the NavigateToTorrentsMsg to fall back to.
+type autoPickedMsg struct {
+	seq  int
+	next tea.Msg
+}
+
+// autoPick tracks the quick-play search running in the background.
+type autoPick struct {
+	active  bool
+	episode int
+	seq     int // ties autoPickedMsg to the search that sent it
+	cancel  context.CancelFunc
+}
+
+// connectivityInterval is how often an offline session checks whether
+// AniList is back.
+const connectivityInterval = 30 * time.Second
+
 // AppModel is the root model that routes to sub-views.
 type AppModel struct {
-	currentView   ViewState
-	viewHistory   []ViewState
-	width         int
-	height        int
-	config        config.Config
-	anilistClient *anilist.Client
-	searchModel   SearchModel
-	detailModel   DetailModel
-	torrentsModel TorrentsModel
-	playerModel   PlayerModel
-	libraryModel  LibraryModel
-	authModel     AuthModel
-	showHelp      bool
-	err           error
+	currentView    ViewState
+	viewHistory    []ViewState
+	width          int
+	height         int
+	config         config.Config
+	anilistClient  *anilist.Client
+	services       Services
+	prefetched     *NavigateToPlayerMsg // next episode warmed up by the last playback
+	searchModel    SearchModel
+	detailModel    DetailModel
+	detailStack    []DetailModel // detail views opened from one another, below detailModel
+	torrentsModel  TorrentsModel
+	playerModel    PlayerModel
+	libraryModel   LibraryModel
+	authModel      AuthModel
+	downloadsModel DownloadsModel
+	queueModel     QueueModel
+	activityModel  ActivityModel
+	statsModel     StatsModel
+	pick           autoPick
+	spinner        spinner.Model // shown in the status bar while pick is active
+	showHelp       bool
+	err            error
 }
 
-// NewAppModel creates the root model with the given config.
-func NewAppModel(cfg config.Config) AppModel {
-	client := anilist.NewClient(cfg.AniListToken)
+// NewAppModel creates the root model with the given config and shared services.
+func NewAppModel(cfg config.Config, svc Services) AppModel {
+	client := newAniListClient(cfg.AniListToken, cfg, svc)
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.SpinnerStyle
 	return AppModel{
 		currentView:   ViewSearch,
 		config:        cfg,
 		anilistClient: client,
+		services:      svc,
 		searchModel:   NewSearchModel(client),
+		spinner:       s,
 	}
 }
 
 func (m AppModel) Init() tea.Cmd {
-	return m.searchModel.Init()
+	return tea.Batch(m.searchModel.Init(), connectivityCmd(m.anilistClient))
+}
+
+// newAniListClient creates a client for the configured user that shares the
+// session's cache, outbox and offline setting.
+func newAniListClient(token string, cfg config.Config, svc Services) *anilist.Client {
+	client := anilist.NewClient(token)
+	client.SetCache(svc.Cache)
+	client.SetOutbox(svc.Outbox)
+	if token != "" {
+		client.SetViewer(cfg.AniListUserID)
+	}
+	if cfg.Offline {
+		client.ForceOffline()
+	}
+	return client
 }
 
 func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -108,6 +216,10 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 		case "esc":
+			if m.pick.active {
+				m.stopAutoPick()
+				return m, nil
+			}
 			if m.activeViewHasError() {
 				return m.propagateMsg(msg)
 			}
@@ -121,10 +233,23 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.currentView == ViewLibrary && m.libraryModel.list.FilterState() == list.Filtering {
 				return m.propagateMsg(msg)
 			}
+			if m.currentView == ViewTorrents && (m.torrentsModel.inputFocused() || m.torrentsModel.infoOpen()) {
+				return m.propagateMsg(msg)
+			}
+			if m.currentView == ViewDetail && m.detailModel.listsOpen() {
+				return m.propagateMsg(msg)
+			}
 			if m.currentView == ViewAuth && (m.authModel.step == authVerifying || m.authModel.step == authSaving) {
 				return m, nil
 			}
+			if m.currentView == ViewTorrents {
+				m.torrentsModel.Cleanup()
+			}
 			if m.currentView == ViewPlayer {
+				// Esc cancels the binge countdown, which reports playback done.
+				if m.playerModel.countingDown() {
+					return m.propagateMsg(msg)
+				}
 				m.playerModel.Cleanup()
 			}
 			return m.navigateBack()
@@ -140,6 +265,9 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.currentView == ViewLibrary && m.libraryModel.list.FilterState() == list.Filtering {
 				return m.propagateMsg(msg)
 			}
+			if m.currentView == ViewTorrents && m.torrentsModel.inputFocused() {
+				return m.propagateMsg(msg)
+			}
 			m.showHelp = true
 			return m, nil
 		case "tab", "shift+tab":
@@ -156,25 +284,82 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.currentView == ViewLibrary {
 				return m.propagateMsg(msg)
 			}
+			// Downloads and the queue swap in place rather than stacking.
+			if m.currentView == ViewDownloads {
+				m.currentView = ViewQueue
+				m.queueModel = NewQueueModel(m.services.Queue, m.services.TorrentErr)
+				return m, m.queueModel.Init()
+			}
+			if m.currentView == ViewQueue {
+				m.currentView = ViewDownloads
+				m.downloadsModel = NewDownloadsModel(m.services.Downloads, m.services.Torrents)
+				return m, m.downloadsModel.Init()
+			}
+		case "d":
+			if m.currentView == ViewSearch && !m.searchModel.inputFocused() {
+				return m.Update(NavigateToDownloadsMsg{})
+			}
+		case "a":
+			if m.currentView == ViewSearch && !m.searchModel.inputFocused() {
+				return m.Update(NavigateToActivityMsg{})
+			}
+		case "s":
+			if m.currentView == ViewSearch && !m.searchModel.inputFocused() {
+				return m.Update(NavigateToStatsMsg{})
+			}
 		}
 
 	case NavigateToDetailMsg:
+		if m.currentView == ViewDetail {
+			// Opened from a related anime: keep this one to come back to.
+			m.detailStack = append(m.detailStack, m.detailModel)
+		}
 		m = m.pushView(ViewDetail)
-		m.detailModel = NewDetailModel(m.anilistClient, msg.AnimeID)
+		m.detailModel = NewDetailModel(m.anilistClient, msg.AnimeID, m.services)
 		return m, m.detailModel.Init()
 
 	case NavigateToTorrentsMsg:
+		// A fully downloaded episode plays straight from disk.
+		if e, ok := m.services.Downloads.Lookup(msg.AnimeID, msg.Request.Episode); ok {
+			return m.Update(NavigateToPlayerMsg{
+				Source:      e.Path,
+				AnimeID:     msg.AnimeID,
+				AnimeTitle:  msg.Request.PrimaryTitle,
+				Episode:     msg.Request.Episode,
+				Request:     msg.Request,
+				Release:     filepath.Base(e.Path),
+				LastEpisode: msg.LastEpisode,
+			})
+		}
+		// Offline, only downloaded episodes can play; the detail view says so.
+		if m.anilistClient.Offline() {
+			return m, nil
+		}
+		// The episode prefetched during the last playback is already buffering.
+		if next := m.prefetched; next != nil && next.AnimeID == msg.AnimeID && next.Episode == msg.Request.Episode {
+			m.prefetched = nil
+			return m.Update(*next)
+		}
+		if msg.AutoPick {
+			return m.startAutoPick(msg)
+		}
 		m = m.pushView(ViewTorrents)
 		req := msg.Request
 		req.Quality = m.config.PreferredQuality
-		m.torrentsModel = NewTorrentsModel(msg.AnimeID, req)
+		m.torrentsModel.Cleanup()
+		m.torrentsModel = NewTorrentsModel(msg.AnimeID, req, msg.LastEpisode, m.services.Queue, rankingProfile(m.config))
 		return m, m.torrentsModel.Init()
 
 	case NavigateToPlayerMsg:
 		m = m.pushView(ViewPlayer)
-		m.playerModel = NewPlayerModel(msg.MagnetURI, msg.AnimeTitle, msg.Episode, msg.AnimeID, m.config)
+		m.playerModel = NewPlayerModel(msg, m.config, m.services)
 		return m, m.playerModel.Init()
 
+	case NavigateToDownloadsMsg:
+		m = m.pushView(ViewDownloads)
+		m.downloadsModel = NewDownloadsModel(m.services.Downloads, m.services.Torrents)
+		return m, m.downloadsModel.Init()
+
 	case NavigateBackMsg:
 		return m.navigateBack()
 
@@ -188,10 +373,27 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.libraryModel = NewLibraryModel(m.anilistClient, m.config.AniListUserID)
 		return m, m.libraryModel.Init()
 
+	case NavigateToActivityMsg:
+		if m.config.AniListToken == "" {
+			m = m.pushView(ViewAuth)
+			m.authModel = NewAuthModel()
+			return m, m.authModel.Init()
+		}
+		m = m.pushView(ViewActivity)
+		m.activityModel = NewActivityModel(m.anilistClient)
+		return m, m.activityModel.Init()
+
+	case NavigateToStatsMsg:
+		// Local statistics are shown without logging in.
+		m = m.pushView(ViewStats)
+		m.statsModel = NewStatsModel(m.anilistClient, m.config.AniListUserID, m.services)
+		m.statsModel, _ = m.statsModel.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		return m, m.statsModel.Init()
+
 	case AuthCompleteMsg:
 		m.config.AniListToken = msg.Token
 		m.config.AniListUserID = msg.UserID
-		m.anilistClient = anilist.NewClient(msg.Token)
+		m.anilistClient = newAniListClient(msg.Token, m.config, m.services)
 		// Pop the auth view and push to library
 		if len(m.viewHistory) > 0 {
 			m.currentView = m.viewHistory[len(m.viewHistory)-1]
@@ -207,28 +409,71 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if msg.session != nil {
 				msg.session.Close()
 			}
-			if msg.torrentClient != nil {
-				msg.torrentClient.Close()
+			if msg.streaming {
+				m.services.Torrents.StopStream()
 			}
 			return m, nil
 		}
 		return m.propagateMsg(msg)
 
 	case PlayerDoneMsg:
+		m.prefetched = msg.Next
 		// Navigate back from player
 		if len(m.viewHistory) > 0 {
 			m.currentView = m.viewHistory[len(m.viewHistory)-1]
 			m.viewHistory = m.viewHistory[:len(m.viewHistory)-1]
 		}
+		var cmds []tea.Cmd
 		// Fire progress update if authenticated
 		if m.config.AniListToken != "" && msg.AnimeID > 0 {
-			return m, updateProgressCmd(m.anilistClient, msg.AnimeID, msg.Episode)
+			cmds = append(cmds, updateProgressCmd(m.anilistClient, msg.AnimeID, msg.Episode))
 		}
-		return m, nil
+		if msg.Binge && (msg.LastEpisode <= 0 || msg.Episode < msg.LastEpisode) {
+			req := msg.Request
+			req.Episode = msg.Episode + 1
+			req.Query = ""
+			next := NavigateToTorrentsMsg{AnimeID: msg.AnimeID, Request: req, AutoPick: true, Release: msg.Release, LastEpisode: msg.LastEpisode}
+			cmds = append(cmds, func() tea.Msg { return next })
+		}
+		return m, tea.Batch(cmds...)
 
 	case updateProgressMsg:
 		// Silent handler — best-effort sync
+		if msg.err == nil {
+			m.detailModel = m.detailModel.watched(msg.animeID, msg.episode)
+		}
 		return m, nil
+
+	case connectivityMsg:
+		next := tea.Tick(connectivityInterval, func(time.Time) tea.Msg { return connectivityTickMsg{} })
+		if msg.online && m.services.Outbox.Len() > 0 {
+			return m, tea.Batch(next, flushOutboxCmd(m.anilistClient))
+		}
+		return m, next
+
+	case connectivityTickMsg:
+		return m, connectivityCmd(m.anilistClient)
+
+	case outboxFlushedMsg:
+		// Anything left is retried after the next connectivity check.
+		return m, nil
+
+	case autoPickedMsg:
+		if !m.pick.active || msg.seq != m.pick.seq {
+			return m, nil // cancelled or superseded
+		}
+		m.stopAutoPick()
+		return m.Update(msg.next)
+
+	case spinner.TickMsg:
+		if msg.ID == m.spinner.ID() {
+			if !m.pick.active {
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
 	}
 
 	return m.propagateMsg(msg)
@@ -239,7 +484,15 @@ func (m AppModel) View() string {
 		return ""
 	}
 
-	header := ui.RenderHeader(m.width)
+	var badges []string
+	if m.anilistClient.Offline() {
+		badge := "OFFLINE"
+		if n := m.services.Outbox.Len(); n > 0 {
+			badge += fmt.Sprintf(" · %d queued", n)
+		}
+		badges = append(badges, badge)
+	}
+	header := ui.RenderHeader(m.width, badges...)
 
 	// Content area = total height - header (1 line) - status bar (1 line)
 	contentHeight := m.height - 2
@@ -253,22 +506,34 @@ func (m AppModel) View() string {
 	switch m.currentView {
 	case ViewSearch:
 		content = m.searchModel.View(m.width, contentHeight)
-		status = "/ search  |  tab library  |  ? help  |  q quit"
+		status = "/ search  |  tab library  |  a activity  |  s stats  |  d downloads  |  ? help  |  q quit"
 	case ViewDetail:
 		content = m.detailModel.View(m.width, contentHeight)
-		status = "j/k navigate  |  enter select  |  ? help  |  esc back"
+		status = "j/k navigate  |  enter select  |  p play best  |  tab related  |  f favourite  |  L lists  |  ? help  |  esc back"
 	case ViewTorrents:
 		content = m.torrentsModel.View(m.width, contentHeight)
-		status = "j/k navigate  |  enter stream  |  ? help  |  esc back"
+		status = "enter stream  |  a queue  |  i details  |  e edit query  |  s sort  |  / filter  |  ? help  |  esc back"
 	case ViewPlayer:
 		content = m.playerModel.View(m.width, contentHeight)
-		status = "? help  |  esc back"
+		status = "t throttle  |  b binge  |  ? help  |  esc back"
 	case ViewLibrary:
 		content = m.libraryModel.View(m.width, contentHeight)
-		status = "tab category  |  enter select  |  ? help  |  esc back"
+		status = "tab list  |  s sort  |  / filter all  |  enter select  |  ? help  |  esc back"
 	case ViewAuth:
 		content = m.authModel.View(m.width, contentHeight)
 		status = "AniList login  |  esc back"
+	case ViewDownloads:
+		content = m.downloadsModel.View(m.width, contentHeight)
+		status = "enter play  |  x delete  |  tab queue  |  ? help  |  esc back"
+	case ViewQueue:
+		content = m.queueModel.View(m.width, contentHeight)
+		status = "p pause  |  x cancel  |  J/K reorder  |  +/- priority  |  tab downloads  |  esc back"
+	case ViewActivity:
+		content = m.activityModel.View(m.width, contentHeight)
+		status = "enter details  |  n more  |  ctrl+r refresh  |  ? help  |  esc back"
+	case ViewStats:
+		content = m.statsModel.View(m.width, contentHeight)
+		status = "j/k scroll  |  ctrl+r refresh  |  ? help  |  esc back"
 	default:
 		content = "Not implemented yet"
 		status = ""
@@ -277,6 +542,9 @@ func (m AppModel) View() string {
 	if m.showHelp {
 		content = m.renderHelpOverlay(m.width, contentHeight)
 	}
+	if m.pick.active {
+		status = fmt.Sprintf("%s Finding a release for episode %d...  |  esc cancel", m.spinner.View(), m.pick.episode)
+	}
 
 	statusBar := ui.RenderStatusBar(m.width, status)
 	return header + "\n" + content + "\n" + statusBar
@@ -303,37 +571,101 @@ func (m AppModel) renderHelpOverlay(width, height int) string {
 			{"/", "Focus search input"},
 			{"enter", "Search / select anime"},
 			{"j/k", "Navigate results"},
+			{"h/l", "Previous / next page (also b / f)"},
+			{"ctrl+r", "Refresh results"},
 			{"tab", "Open library"},
+			{"a", "Open friends' activity"},
+			{"s", "Open stats"},
+			{"d", "Open downloads"},
 			{"esc", "Unfocus input / quit"},
 			{"q", "Quit"},
 		}
 	case ViewDetail:
 		bindings = []binding{
-			{"j/k", "Navigate episodes"},
+			{"j/k", "Navigate episodes / related"},
 			{"g/G", "First / last episode"},
-			{"enter", "Search torrents for episode"},
+			{"tab", "Switch between episodes and related"},
+			{"[ / ]", "Overview / characters / staff / stats"},
+			{"v", "Cycle voice actor language"},
+			{"enter", "Search torrents / open related"},
+			{"p", "Play best match (skip list)"},
+			{"f", "Add to / remove from favourites"},
+			{"L", "Add to / remove from custom lists"},
+			{"ctrl+r", "Refresh details"},
 			{"esc", "Go back"},
 		}
 	case ViewTorrents:
 		bindings = []binding{
 			{"j/k", "Navigate torrents"},
 			{"enter", "Stream selected torrent"},
+			{"a", "Add to download queue"},
+			{"i", "Show / hide torrent details"},
+			{"n", "Load next results page"},
+			{"ctrl+r", "Search again, skipping the cache"},
+			{"e", "Edit search query"},
+			{"c", "Cycle nyaa category"},
+			{"F", "Cycle nyaa filter"},
+			{"S", "Cycle nyaa sort (re-search)"},
+			{"s", "Cycle sort order"},
+			{"/", "Filter titles"},
+			{"t", "Toggle trusted only"},
+			{"m", "Toggle hide remakes"},
+			{"r", "Cycle resolution filter"},
+			{"B", "Toggle hide batches"},
+			{"o", "Open magnet, .torrent or file"},
 			{"esc", "Go back"},
 		}
 	case ViewPlayer:
 		bindings = []binding{
+			{"t", "Toggle bandwidth throttle"},
+			{"b", "Toggle binge mode"},
 			{"esc", "Stop playback and go back"},
 		}
 	case ViewLibrary:
 		bindings = []binding{
-			{"tab", "Next category"},
-			{"shift+tab", "Previous category"},
-			{"/", "Filter list"},
-			{"r", "Refresh library"},
+			{"tab", "Next list"},
+			{"shift+tab", "Previous list"},
+			{"s", "Cycle sort order"},
+			{"/", "Filter all lists"},
+			{"r/ctrl+r", "Refresh library"},
 			{"enter", "View anime details"},
 			{"q", "Quit"},
 			{"esc", "Go back"},
 		}
+	case ViewDownloads:
+		bindings = []binding{
+			{"j/k", "Navigate downloads"},
+			{"enter", "Play episode"},
+			{"x x", "Delete file from disk"},
+			{"r", "Refresh list"},
+			{"tab", "Show download queue"},
+			{"esc", "Go back"},
+		}
+	case ViewQueue:
+		bindings = []binding{
+			{"j/k", "Navigate queue"},
+			{"p/space", "Pause / resume"},
+			{"x x", "Cancel download"},
+			{"J/K", "Move down / up"},
+			{"+/-", "Raise / lower priority"},
+			{"tab", "Show downloads"},
+			{"esc", "Go back"},
+		}
+	case ViewActivity:
+		bindings = []binding{
+			{"j/k", "Navigate activity"},
+			{"enter", "View anime details"},
+			{"n", "Load older activity"},
+			{"ctrl+r", "Refresh activity"},
+			{"esc", "Go back"},
+		}
+	case ViewStats:
+		bindings = []binding{
+			{"j/k", "Scroll"},
+			{"g/G", "Top / bottom"},
+			{"ctrl+r", "Refresh stats"},
+			{"esc", "Go back"},
+		}
 	}
 
 	// Always-available bindings
@@ -373,12 +705,22 @@ func (m AppModel) activeViewHasError() bool {
 		return m.playerModel.err != nil
 	case ViewLibrary:
 		return m.libraryModel.err != nil
+	case ViewDownloads:
+		return m.downloadsModel.err != nil
+	case ViewQueue:
+		return m.queueModel.err != nil
+	case ViewActivity:
+		return m.activityModel.err != nil
+	case ViewStats:
+		return m.statsModel.err != nil
 	}
 	return false
 }
 
 // cleanup releases resources before quitting.
 func (m *AppModel) cleanup() {
+	m.stopAutoPick()
+	m.torrentsModel.Cleanup()
 	m.playerModel.Cleanup()
 }
 
@@ -388,9 +730,20 @@ func (m AppModel) navigateBack() (tea.Model, tea.Cmd) {
 		m.cleanup()
 		return m, tea.Quit
 	}
-	m.currentView = m.viewHistory[len(m.viewHistory)-1]
+	prev := m.viewHistory[len(m.viewHistory)-1]
 	m.viewHistory = m.viewHistory[:len(m.viewHistory)-1]
-	return m, nil
+	var cmd tea.Cmd
+	if m.currentView == ViewDetail && prev == ViewDetail && len(m.detailStack) > 0 {
+		m.detailModel = m.detailStack[len(m.detailStack)-1]
+		m.detailStack = m.detailStack[:len(m.detailStack)-1]
+		cmd = m.detailModel.reopen()
+	}
+	if prev == ViewDetail {
+		// Episodes may have been downloaded or deleted meanwhile.
+		m.detailModel = m.detailModel.refreshDownloaded(false)
+	}
+	m.currentView = prev
+	return m, cmd
 }
 
 // propagateMsg forwards the message to the current sub-model.
@@ -420,6 +773,22 @@ func (m AppModel) propagateMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
 		am, cmd := m.authModel.Update(msg)
 		m.authModel = am
 		return m, cmd
+	case ViewDownloads:
+		dm, cmd := m.downloadsModel.Update(msg)
+		m.downloadsModel = dm
+		return m, cmd
+	case ViewQueue:
+		qm, cmd := m.queueModel.Update(msg)
+		m.queueModel = qm
+		return m, cmd
+	case ViewActivity:
+		am, cmd := m.activityModel.Update(msg)
+		m.activityModel = am
+		return m, cmd
+	case ViewStats:
+		sm, cmd := m.statsModel.Update(msg)
+		m.statsModel = sm
+		return m, cmd
 	}
 	return m, nil
 }
@@ -427,6 +796,69 @@ func (m AppModel) propagateMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
 func updateProgressCmd(client *anilist.Client, animeID, episode int) tea.Cmd {
 	return func() tea.Msg {
 		err := client.UpdateProgress(context.Background(), animeID, episode, "CURRENT")
-		return updateProgressMsg{err: err}
+		return updateProgressMsg{animeID: animeID, episode: episode, err: err}
+	}
+}
+
+// connectivityCmd checks whether AniList is back when the session went
+// offline on its own. A forced offline session never checks.
+func connectivityCmd(client *anilist.Client) tea.Cmd {
+	return func() tea.Msg {
+		if client.Offline() && !client.Forced() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = client.Ping(ctx)
+		}
+		return connectivityMsg{online: !client.Offline()}
+	}
+}
+
+// flushOutboxCmd sends list changes queued while offline.
+func flushOutboxCmd(client *anilist.Client) tea.Cmd {
+	return func() tea.Msg {
+		sent, err := client.FlushOutbox(context.Background())
+		return outboxFlushedMsg{sent: sent, err: err}
+	}
+}
+
+// startAutoPick looks for the release to play in the background, showing
+// progress in the status bar until it finishes or esc cancels it.
+func (m AppModel) startAutoPick(msg NavigateToTorrentsMsg) (AppModel, tea.Cmd) {
+	m.stopAutoPick()
+	ctx, cancel := context.WithTimeout(context.Background(), streamTimeout)
+	m.pick = autoPick{active: true, episode: msg.Request.Episode, seq: m.pick.seq + 1, cancel: cancel}
+	return m, tea.Batch(m.spinner.Tick, autoPickCmd(ctx, msg, m.config, m.pick.seq))
+}
+
+// stopAutoPick cancels the running auto-pick search, if any.
+func (m *AppModel) stopAutoPick() {
+	if m.pick.cancel != nil {
+		m.pick.cancel()
+	}
+	m.pick.active = false
+	m.pick.cancel = nil
+}
+
+// autoPickCmd plays the best release of the requested episode, preferring
+// msg.Release's group and resolution when set, or opens the torrent list when
+// nothing matches.
+func autoPickCmd(ctx context.Context, msg NavigateToTorrentsMsg, cfg config.Config, seq int) tea.Cmd {
+	return func() tea.Msg {
+		next := NavigateToPlayerMsg{
+			AnimeID:     msg.AnimeID,
+			AnimeTitle:  msg.Request.PrimaryTitle,
+			Episode:     msg.Request.Episode,
+			Request:     msg.Request,
+			LastEpisode: msg.LastEpisode,
+		}
+		if cfg.PreferredQuality != "" && msg.Release == "" {
+			next.Request.Quality = cfg.PreferredQuality
+		}
+		next, err := findRelease(ctx, next, nyaa.ParseRelease(msg.Release), rankingProfile(cfg))
+		if err != nil {
+			msg.AutoPick = false
+			return autoPickedMsg{seq: seq, next: msg}
+		}
+		return autoPickedMsg{seq: seq, next: next}
 	}
 }
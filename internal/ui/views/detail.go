@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"html"
+	"image"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -12,19 +15,94 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/rayanxn/ani-tui/internal/anilist"
+	"github.com/rayanxn/ani-tui/internal/cache"
+	"github.com/rayanxn/ani-tui/internal/downloads"
+	"github.com/rayanxn/ani-tui/internal/history"
 	"github.com/rayanxn/ani-tui/internal/nyaa"
+	"github.com/rayanxn/ani-tui/internal/termimg"
 	"github.com/rayanxn/ani-tui/internal/ui"
 )
 
-// AnimeDetailsMsg carries the result of fetching anime details.
+// AnimeDetailsMsg carries the result of fetching anime details. Stale
+// details come from the cache and are being refreshed.
 type AnimeDetailsMsg struct {
-	Media anilist.Media
-	Err   error
+	Media   anilist.Media
+	Err     error
+	Stale   bool
+	animeID int
+	refresh bool // fetched to replace details already shown
+}
+
+// favouriteToggledMsg reports a ToggleFavourite mutation. favourite is the
+// state it was meant to set.
+type favouriteToggledMsg struct {
+	animeID   int
+	favourite bool
+	err       error
+}
+
+// episodeOffsetMsg carries the absolute numbering offset of an anime.
+type episodeOffsetMsg struct {
+	animeID    int
+	offset     int
+	baseTitles []string
+	err        error
+}
+
+// coverMsg carries a downloaded cover image.
+type coverMsg struct {
+	animeID int
+	img     image.Image
+	cellW   int
+	cellH   int
+	err     error
+}
+
+// coverArt is a decoded cover and its last rendering, which is reused while
+// the layout keeps the same size.
+type coverArt struct {
+	img          image.Image
+	cellW, cellH int
+	cols, rows   int
+	rendered     string
+}
+
+// Cover art is shown beside the metadata only when the left panel is at least
+// coverMinWidth wide, leaving room for the text.
+const (
+	coverCols     = 20
+	coverMinWidth = 60
+	coverMinRows  = 6
+)
+
+// detailFocus is the part of the detail view that j/k and enter act on.
+type detailFocus int
+
+const (
+	focusEpisodes detailFocus = iota
+	focusRelated
+)
+
+// relatedTypes are the relations listed in the detail view, in order.
+var relatedTypes = []string{"PREQUEL", "SEQUEL", "PARENT", "SIDE_STORY", "SPIN_OFF", "ALTERNATIVE"}
+
+// relatedEntry is a related or recommended anime that can be opened from
+// the detail view.
+type relatedEntry struct {
+	animeID int
+	kind    string // e.g. "Sequel" or "Recommended"
+	title   string
+	info    string // format and year
 }
 
 // DetailModel displays anime metadata and an episode selector.
 type DetailModel struct {
 	client          *anilist.Client
+	downloads       *downloads.Index
+	history         *history.Store
+	cache           *cache.Cache
+	graphics        termimg.Protocol
+	cover           *coverArt
 	animeID         int
 	media           anilist.Media
 	viewport        viewport.Model
@@ -32,18 +110,39 @@ type DetailModel struct {
 	loading         bool
 	err             error
 	selectedEpisode int // 1-indexed
-	totalEpisodes   int
-	scrollOffset    int // for scrolling the episode list
+	totalEpisodes   int // listed episodes, including ones not aired yet
+	airedEpisodes   int
+	progress        int // episodes watched according to AniList
+	episodeTitles   map[int]anilist.StreamingEpisode
+	related         []relatedEntry
+	relatedCursor   int
+	focus           detailFocus
+	tab             detailTab
+	characters      creditList
+	staff           creditList
+	language        int      // index into voiceLanguages
+	scrollOffset    int      // for scrolling the episode list
+	episodeOffset   int      // episodes in earlier seasons, for absolute numbering
+	baseTitles      []string // first season's titles, used with absolute numbers
+	notice          string
+	lists           listPicker
+	onDisk          map[int]bool // downloaded episodes, as of downloadsVer
+	downloadsVer    uint64
 }
 
-// NewDetailModel creates a detail view for the given anime ID.
-func NewDetailModel(client *anilist.Client, animeID int) DetailModel {
+// NewDetailModel creates a detail view for the given anime ID. Downloaded
+// and partially watched episodes are looked up in the shared services.
+func NewDetailModel(client *anilist.Client, animeID int, svc Services) DetailModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = ui.SpinnerStyle
 
 	return DetailModel{
 		client:          client,
+		downloads:       svc.Downloads,
+		history:         svc.History,
+		cache:           svc.Cache,
+		graphics:        svc.Graphics,
 		animeID:         animeID,
 		loading:         true,
 		selectedEpisode: 1,
@@ -54,27 +153,116 @@ func NewDetailModel(client *anilist.Client, animeID int) DetailModel {
 func (m DetailModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
-		fetchAnimeDetailsCmd(m.client, m.animeID),
+		fetchAnimeDetailsCmd(m.client, m.animeID, false),
 	)
 }
 
+// reopen resumes a detail view brought back from the stack. Replies that
+// arrived while it was hidden went to the view above it, so loads still
+// pending are started again.
+func (m DetailModel) reopen() tea.Cmd {
+	var cmds []tea.Cmd
+	if m.loading {
+		cmds = append(cmds, m.Init())
+	}
+	for _, tab := range []detailTab{tabCharacters, tabStaff} {
+		if m.credits(tab).loading {
+			cmds = append(cmds, m.fetchCredits(tab))
+		}
+	}
+	if m.lists.open && m.lists.loading {
+		cmds = append(cmds, fetchCustomListsCmd(m.client, m.animeID))
+	}
+	return tea.Batch(cmds...)
+}
+
 func (m DetailModel) Update(msg tea.Msg) (DetailModel, tea.Cmd) {
+	m = m.refreshDownloaded(false)
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		return m, nil
 
 	case AnimeDetailsMsg:
+		if msg.animeID != m.animeID {
+			return m, nil // meant for a detail view further down the stack
+		}
 		m.loading = false
 		if msg.Err != nil {
-			m.err = msg.Err
+			// A failed refresh keeps the cached details on screen.
+			if !msg.refresh || m.media.ID == 0 {
+				m.err = msg.Err
+			}
 			return m, nil
 		}
+		shown := m.media.ID == msg.Media.ID
 		m.media = msg.Media
-		m.totalEpisodes = availableEpisodes(msg.Media)
-		if m.totalEpisodes > 0 {
-			m.selectedEpisode = 1
-		} else {
+		m = m.refreshDownloaded(true)
+		m.airedEpisodes = availableEpisodes(msg.Media)
+		m.totalEpisodes = listedEpisodes(msg.Media)
+		m.progress = m.watchedProgress()
+		m.episodeTitles = msg.Media.EpisodeTitles()
+		m.related = relatedEntries(msg.Media)
+		m.relatedCursor = min(m.relatedCursor, max(0, len(m.related)-1))
+		if len(m.related) == 0 {
+			m.focus = focusEpisodes
+		}
+		switch {
+		case m.totalEpisodes <= 0:
 			m.selectedEpisode = 0
+		case !shown || m.selectedEpisode < 1:
+			// Open on the first episode left to watch.
+			m.selectedEpisode = min(max(m.progress+1, 1), m.totalEpisodes)
+		default:
+			m.selectedEpisode = min(m.selectedEpisode, m.totalEpisodes)
+		}
+
+		var cmds []tea.Cmd
+		if msg.Stale {
+			cmds = append(cmds, fetchAnimeDetailsCmd(m.client, m.animeID, true))
+		}
+		if _, ok := msg.Media.Prequel(); ok && !shown {
+			cmds = append(cmds, fetchEpisodeOffsetCmd(m.client, msg.Media))
+		}
+		if url := msg.Media.CoverImage.Large; url != "" && m.graphics != termimg.None && m.cover == nil {
+			cmds = append(cmds, fetchCoverCmd(m.cache, m.animeID, url))
+		}
+		return m, tea.Batch(cmds...)
+
+	case creditsMsg:
+		return m.addCredits(msg), nil
+
+	case customListsMsg:
+		return m.addListNames(msg), nil
+
+	case customListsSavedMsg:
+		if msg.animeID == m.animeID && msg.err != nil {
+			m.media.MediaListEntry = msg.prev
+			m.lists.err = msg.err
+			if !m.lists.open {
+				m.notice = "Couldn't update custom lists: " + msg.err.Error()
+			}
+		}
+		return m, nil
+
+	case favouriteToggledMsg:
+		if msg.animeID == m.animeID && msg.err != nil {
+			m.media.IsFavourite = !msg.favourite
+			m.notice = "Couldn't update favourites: " + msg.err.Error()
+		}
+		return m, nil
+
+	case coverMsg:
+		// Best-effort: without a cover the metadata takes the full width.
+		if msg.animeID == m.animeID && msg.err == nil {
+			m.cover = &coverArt{img: msg.img, cellW: msg.cellW, cellH: msg.cellH}
+		}
+		return m, nil
+
+	case episodeOffsetMsg:
+		// Best-effort: without an offset only per-season numbers are searched.
+		if msg.animeID == m.animeID && msg.err == nil {
+			m.episodeOffset = msg.offset
+			m.baseTitles = msg.baseTitles
 		}
 		return m, nil
 
@@ -97,7 +285,60 @@ func (m DetailModel) Update(msg tea.Msg) (DetailModel, tea.Cmd) {
 			}
 			return m, nil
 		}
+		if m.lists.open {
+			return m.updateLists(msg.String())
+		}
 		switch msg.String() {
+		case "[":
+			return m.switchTab(-1)
+		case "]":
+			return m.switchTab(1)
+		case "ctrl+r":
+			return m, fetchAnimeDetailsCmd(m.client, m.animeID, true)
+		case "f":
+			if !m.client.Authenticated() {
+				m.notice = "Log in to AniList to add favourites"
+				return m, nil
+			}
+			// Shown straight away; a failed mutation puts it back.
+			m.media.IsFavourite = !m.media.IsFavourite
+			m.notice = ""
+			return m, toggleFavouriteCmd(m.client, m.animeID, m.media.IsFavourite)
+		case "L":
+			return m.openLists()
+		}
+		if m.tab != tabOverview {
+			return m.updateTab(msg.String())
+		}
+		if m.focus == focusRelated {
+			switch msg.String() {
+			case "j", "down":
+				m.relatedCursor = min(m.relatedCursor+1, len(m.related)-1)
+				return m, nil
+			case "k", "up":
+				m.relatedCursor = max(m.relatedCursor-1, 0)
+				return m, nil
+			case "g":
+				m.relatedCursor = 0
+				return m, nil
+			case "G":
+				m.relatedCursor = len(m.related) - 1
+				return m, nil
+			case "enter":
+				id := m.related[m.relatedCursor].animeID
+				return m, func() tea.Msg { return NavigateToDetailMsg{AnimeID: id} }
+			case "p":
+				return m, nil
+			}
+		}
+		switch msg.String() {
+		case "tab":
+			if m.focus == focusEpisodes && len(m.related) > 0 {
+				m.focus = focusRelated
+			} else {
+				m.focus = focusEpisodes
+			}
+			return m, nil
 		case "j", "down":
 			if m.totalEpisodes > 0 && m.selectedEpisode < m.totalEpisodes {
 				m.selectedEpisode++
@@ -118,19 +359,36 @@ func (m DetailModel) Update(msg tea.Msg) (DetailModel, tea.Cmd) {
 				m.selectedEpisode = m.totalEpisodes
 			}
 			return m, nil
-		case "enter":
+		case "enter", "p":
 			if m.selectedEpisode <= 0 {
 				return m, nil
 			}
-			altTitles := collectTitles(m.media.Title)
+			if m.selectedEpisode > m.airedEpisodes {
+				m.notice = fmt.Sprintf("Episode %d hasn't aired yet", m.selectedEpisode)
+				if at, ok := m.airDate(m.selectedEpisode); ok {
+					m.notice = fmt.Sprintf("Episode %d airs %s", m.selectedEpisode, at)
+				}
+				return m, nil
+			}
+			if !m.playable(m.selectedEpisode) {
+				m.notice = "Offline: only downloaded episodes can be played"
+				return m, nil
+			}
+			m.notice = ""
+			altTitles := collectTitles(m.media)
+			autoPick := msg.String() == "p"
 			return m, func() tea.Msg {
 				return NavigateToTorrentsMsg{
 					AnimeID: m.animeID,
 					Request: nyaa.SearchRequest{
-						PrimaryTitle: m.media.Title.DisplayTitle(),
-						AltTitles:    altTitles,
-						Episode:      m.selectedEpisode,
+						PrimaryTitle:  m.media.Title.DisplayTitle(),
+						AltTitles:     altTitles,
+						Episode:       m.selectedEpisode,
+						EpisodeOffset: m.episodeOffset,
+						BaseTitles:    m.baseTitles,
 					},
+					AutoPick:    autoPick,
+					LastEpisode: m.airedEpisodes,
 				}
 			}
 		}
@@ -157,17 +415,19 @@ func (m DetailModel) View(width, height int) string {
 	return m.renderHorizontal(width, height)
 }
 
-// renderHorizontal renders side-by-side: left 2/3 metadata, right 1/3 episodes.
+// renderHorizontal renders side-by-side: left 2/3 cover art and metadata,
+// right 1/3 episodes.
 func (m DetailModel) renderHorizontal(width, height int) string {
 	leftWidth := width*2/3 - 2
 	rightWidth := width - leftWidth - 1
 
-	metaContent := m.renderMetadata(leftWidth - 4)
-	m.viewport = viewport.New(leftWidth, height)
-	m.viewport.SetContent(metaContent)
-	m.viewport.Style = lipgloss.NewStyle().Padding(0, 2)
+	cover := m.renderCover(leftWidth, height)
+	metaWidth := leftWidth - lipgloss.Width(cover)
 
-	leftPanel := m.viewport.View()
+	leftPanel := m.renderLeftPanel(metaWidth, height)
+	if cover != "" {
+		leftPanel = lipgloss.JoinHorizontal(lipgloss.Top, cover, leftPanel)
+	}
 
 	episodePanel := m.renderEpisodeSelector(rightWidth, height)
 
@@ -178,25 +438,78 @@ func (m DetailModel) renderHorizontal(width, height int) string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, separator, episodePanel)
 }
 
-// renderVertical renders stacked: metadata on top, episodes below.
+// renderVertical renders stacked: metadata on top, episodes below. There is
+// no room for cover art.
 func (m DetailModel) renderVertical(width, height int) string {
 	metaHeight := height * 2 / 3
 	epHeight := height - metaHeight
 
-	metaContent := m.renderMetadata(width - 4)
-	m.viewport = viewport.New(width, metaHeight)
-	m.viewport.SetContent(metaContent)
-	m.viewport.Style = lipgloss.NewStyle().Padding(0, 2)
-
-	leftPanel := m.viewport.View()
+	leftPanel := m.renderLeftPanel(width, metaHeight)
 
 	episodePanel := m.renderEpisodeSelector(width, epHeight)
 
 	return lipgloss.JoinVertical(lipgloss.Left, leftPanel, episodePanel)
 }
 
-// renderMetadata formats the anime metadata block.
-func (m DetailModel) renderMetadata(width int) string {
+// renderCover draws the cover art, indented to line up with the metadata,
+// or returns "" when there is none or it doesn't fit in width×height.
+func (m DetailModel) renderCover(width, height int) string {
+	c := m.cover
+	if c == nil || width < coverMinWidth {
+		return ""
+	}
+	cols := coverCols
+	rows := termimg.FitRows(c.img, cols, c.cellW, c.cellH)
+	for rows > height && cols > 1 {
+		cols--
+		rows = termimg.FitRows(c.img, cols, c.cellW, c.cellH)
+	}
+	if rows < coverMinRows {
+		return ""
+	}
+
+	// Encoding is slow enough to notice, so reuse it while the size holds.
+	if c.cols != cols || c.rows != rows {
+		block := termimg.Render(c.img, termimg.Options{
+			Protocol:   m.graphics,
+			Cols:       cols,
+			Rows:       rows,
+			CellWidth:  c.cellW,
+			CellHeight: c.cellH,
+			ID:         m.animeID%255 + 1,
+		})
+		lines := strings.Split(block, "\n")
+		for i, l := range lines {
+			lines[i] = "  " + l
+		}
+		c.cols, c.rows, c.rendered = cols, rows, strings.Join(lines, "\n")
+	}
+	return c.rendered
+}
+
+// renderLeftPanel renders the tab bar over the current tab, scrolled to keep
+// any selected row in view.
+func (m DetailModel) renderLeftPanel(width, height int) string {
+	content, cursorLine := m.renderTab(width - 4)
+	m.viewport = viewport.New(width, max(1, height-2))
+	m.viewport.SetContent(content)
+	m.viewport.Style = lipgloss.NewStyle().Padding(0, 2)
+	scrollTo(&m.viewport, cursorLine)
+
+	tabBar := lipgloss.NewStyle().Padding(0, 2).MaxWidth(width).Render(m.renderTabBar())
+	return tabBar + "\n\n" + m.viewport.View()
+}
+
+// scrollTo scrolls vp just far enough to show line, if line is set (>= 0).
+func scrollTo(vp *viewport.Model, line int) {
+	if line >= vp.Height {
+		vp.SetYOffset(line - vp.Height + 1)
+	}
+}
+
+// renderMetadata formats the anime metadata block. cursorLine is the line of
+// the selected related anime while they have focus, or -1.
+func (m DetailModel) renderMetadata(width int) (content string, cursorLine int) {
 	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.ColorPrimary)
 	valueStyle := lipgloss.NewStyle().Foreground(ui.ColorText)
 	subtleStyle := lipgloss.NewStyle().Foreground(ui.ColorSubtle)
@@ -205,7 +518,11 @@ func (m DetailModel) renderMetadata(width int) string {
 	var lines []string
 
 	// Title block
-	lines = append(lines, ui.TitleStyle.Render(m.media.Title.DisplayTitle()))
+	title := ui.TitleStyle.Render(m.media.Title.DisplayTitle())
+	if m.media.IsFavourite {
+		title += lipgloss.NewStyle().Foreground(ui.ColorSecondary).Render(" ♥")
+	}
+	lines = append(lines, title)
 	if m.media.Title.Native != "" {
 		lines = append(lines, ui.SubtitleStyle.Render(m.media.Title.Native))
 	}
@@ -283,6 +600,16 @@ func (m DetailModel) renderMetadata(width int) string {
 		lines = append(lines, labelStyle.Render("Studio: ")+valueStyle.Render(strings.Join(names, ", ")))
 	}
 
+	// Selected episode
+	if ep, ok := m.episodeTitles[m.selectedEpisode]; ok && ep.Title != "" {
+		lines = append(lines, divider)
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("Episode %d", m.selectedEpisode)))
+		lines = append(lines, valueStyle.Render(wordWrap(ep.Title, width)))
+		if ep.Site != "" {
+			lines = append(lines, subtleStyle.Render("Streaming on "+ep.Site))
+		}
+	}
+
 	// Synopsis
 	if m.media.Description != "" {
 		lines = append(lines, divider)
@@ -292,18 +619,45 @@ func (m DetailModel) renderMetadata(width int) string {
 		lines = append(lines, subtleStyle.Render(wrapped))
 	}
 
-	return strings.Join(lines, "\n")
+	// Related and recommended anime
+	cursorLine = -1
+	if len(m.related) > 0 {
+		lines = append(lines, divider)
+		hint := "  tab to browse"
+		if m.focus == focusRelated {
+			hint = "  enter open · tab back to episodes"
+		}
+		lines = append(lines, labelStyle.Render("Related")+subtleStyle.Render(hint))
+		kindStyle := lipgloss.NewStyle().Width(12).Foreground(ui.ColorSubtle)
+		for i, r := range m.related {
+			title := truncate(r.title, max(1, width-lipgloss.Width(r.info)-16))
+			if m.focus == focusRelated && i == m.relatedCursor {
+				cursorLine = strings.Count(strings.Join(lines, "\n"), "\n") + 1
+				lines = append(lines, ui.SelectedItemStyle.Render(fmt.Sprintf("▸ %-12s%s", r.kind, title))+subtleStyle.Render("  "+r.info))
+				continue
+			}
+			lines = append(lines, "  "+kindStyle.Render(r.kind)+valueStyle.Render(title)+subtleStyle.Render("  "+r.info))
+		}
+	}
+
+	return strings.Join(lines, "\n"), cursorLine
 }
 
 // renderEpisodeSelector renders the episode list with cursor.
 func (m DetailModel) renderEpisodeSelector(width, height int) string {
+	if m.lists.open {
+		return m.renderLists(width, height)
+	}
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.ColorPrimary).Padding(0, 1)
 	dimStyle := lipgloss.NewStyle().Foreground(ui.ColorSubtle)
 	header := titleStyle.Render("Episodes")
+	if m.client.Offline() {
+		header += dimStyle.Render("(offline: downloaded only)")
+	}
 	divider := "  " + ui.DimDivider(max(0, width-4))
 
-	// Available lines for episode items (minus header, divider, and padding)
-	listHeight := height - 3
+	// Available lines for episode items (minus header, divider, legend, and padding)
+	listHeight := height - 5
 	if listHeight < 1 {
 		listHeight = 1
 	}
@@ -324,15 +678,55 @@ func (m DetailModel) renderEpisodeSelector(width, height int) string {
 				continue
 			}
 
+			label := fmt.Sprintf("Episode %d", i)
+			if m.episodeOffset > 0 {
+				label += fmt.Sprintf(" (#%d)", i+m.episodeOffset)
+			}
+			mark, markStyle := " ", dimStyle
+			switch {
+			case i <= m.progress:
+				mark, markStyle = "✓", lipgloss.NewStyle().Foreground(ui.ColorSuccess)
+			case m.partiallyWatched(i):
+				mark, markStyle = "◐", lipgloss.NewStyle().Foreground(ui.ColorAccent)
+			}
+			suffix := ""
+			if i > m.airedEpisodes {
+				suffix = " · not aired"
+				if at, ok := m.airDate(i); ok {
+					suffix = " · " + at
+				}
+			} else if m.downloaded(i) {
+				suffix = " ↓"
+			}
+			if title := m.episodeTitles[i].Title; title != "" {
+				label = strings.TrimPrefix(label, "Episode ") + " · " + title
+			}
+			// Row prefix ("▸ ✓ ") and the panel's padding take 6 cells.
+			label = truncate(label, width-6-lipgloss.Width(suffix))
+			cursor := "  "
 			if i == m.selectedEpisode {
-				items = append(items, ui.SelectedItemStyle.Render(fmt.Sprintf("▸ Episode %d", i)))
-			} else {
-				items = append(items, dimStyle.Render(fmt.Sprintf("  Episode %d", i)))
+				cursor = "▸ "
+			}
+			switch {
+			case i == m.selectedEpisode && m.focus == focusEpisodes:
+				items = append(items, ui.SelectedItemStyle.Render(cursor+mark+" "+label+suffix))
+			case i > m.airedEpisodes:
+				items = append(items, cursor+markStyle.Render(mark)+" "+dimStyle.Faint(true).Render(label+suffix))
+			case !m.playable(i):
+				items = append(items, cursor+markStyle.Render(mark)+" "+dimStyle.Faint(true).Strikethrough(true).Render(label+suffix))
+			default:
+				items = append(items, cursor+markStyle.Render(mark)+" "+dimStyle.Render(label+suffix))
 			}
 		}
 	}
 
 	content := header + "\n" + divider + "\n" + strings.Join(items, "\n")
+	if m.totalEpisodes > 0 {
+		content += "\n\n" + dimStyle.Faint(true).Render("  ✓ watched  ◐ in progress  ↓ downloaded")
+	}
+	if m.notice != "" {
+		content += "\n\n" + lipgloss.NewStyle().Foreground(ui.ColorError).Padding(0, 1).Render(m.notice)
+	}
 
 	return lipgloss.NewStyle().
 		Width(width).
@@ -342,17 +736,159 @@ func (m DetailModel) renderEpisodeSelector(width, height int) string {
 }
 
 // fetchAnimeDetailsCmd returns a Cmd that fetches anime details from AniList.
-func fetchAnimeDetailsCmd(client *anilist.Client, id int) tea.Cmd {
+// Cached details are returned first unless refresh is set.
+func fetchAnimeDetailsCmd(client *anilist.Client, id int, refresh bool) tea.Cmd {
 	return func() tea.Msg {
+		if !refresh {
+			if media, fresh, ok := client.CachedAnimeDetails(id); ok {
+				return AnimeDetailsMsg{Media: media, Stale: !fresh, animeID: id}
+			}
+		}
 		media, err := client.GetAnimeDetails(context.Background(), id)
-		return AnimeDetailsMsg{Media: media, Err: err}
+		return AnimeDetailsMsg{Media: media, Err: err, animeID: id, refresh: refresh}
+	}
+}
+
+// fetchCoverCmd downloads the cover at url, or loads it from the cache, and
+// measures the terminal's cells to scale it.
+func fetchCoverCmd(c *cache.Cache, animeID int, url string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		img, err := termimg.Fetch(ctx, c, url)
+		w, h := termimg.CellSize()
+		return coverMsg{animeID: animeID, img: img, cellW: w, cellH: h, err: err}
+	}
+}
+
+// playable reports whether episode can be played right now: always when
+// online, and only from disk when offline.
+func (m DetailModel) playable(episode int) bool {
+	return !m.client.Offline() || m.downloaded(episode)
+}
+
+// downloaded reports whether episode is on disk.
+func (m DetailModel) downloaded(episode int) bool {
+	return m.onDisk[episode]
+}
+
+// refreshDownloaded recomputes which episodes are on disk when the index has
+// changed since the last time, or always when force is set.
+func (m DetailModel) refreshDownloaded(force bool) DetailModel {
+	if m.downloads == nil {
+		return m
+	}
+	if v := m.downloads.Version(); force || m.onDisk == nil || v != m.downloadsVer {
+		m.downloadsVer = v
+		m.onDisk = m.downloads.Episodes(m.animeID)
+	}
+	return m
+}
+
+// watchedProgress returns how many episodes the viewer has watched: their
+// AniList progress, or a newer update still queued while offline.
+func (m DetailModel) watchedProgress() int {
+	for _, q := range m.client.Outbox().Pending() {
+		if q.MediaID == m.animeID && q.Kind == anilist.MutationProgress {
+			return q.Progress
+		}
+	}
+	if e := m.media.MediaListEntry; e != nil {
+		return e.Progress
+	}
+	return 0
+}
+
+// watched records that episode of animeID was just watched, so its marker
+// updates without refetching the details.
+func (m DetailModel) watched(animeID, episode int) DetailModel {
+	if animeID == m.animeID {
+		m.progress = max(m.progress, episode)
+	}
+	return m
+}
+
+// partiallyWatched reports whether playback of episode stopped part way.
+func (m DetailModel) partiallyWatched(episode int) bool {
+	p, ok := m.history.Lookup(m.animeID, episode)
+	return ok && p.Partial()
+}
+
+// airDate returns when a not yet aired episode is scheduled, e.g. "Sat Oct 25".
+func (m DetailModel) airDate(episode int) (string, bool) {
+	at, ok := m.media.AiringSchedule.AiringAt(episode)
+	if !ok {
+		if next := m.media.NextAiringEpisode; next != nil && next.Episode == episode {
+			at, ok = next.AiringAt, true
+		}
+	}
+	if !ok || at == 0 {
+		return "", false
+	}
+	return time.Unix(at, 0).Format("Mon Jan 2"), true
+}
+
+// toggleFavouriteCmd adds the anime to the viewer's favourites or removes it.
+func toggleFavouriteCmd(client *anilist.Client, id int, favourite bool) tea.Cmd {
+	return func() tea.Msg {
+		err := client.ToggleFavourite(context.Background(), id)
+		return favouriteToggledMsg{animeID: id, favourite: favourite, err: err}
 	}
 }
 
+// fetchEpisodeOffsetCmd walks the anime's prequels to find how its episodes
+// are numbered in absolute terms.
+func fetchEpisodeOffsetCmd(client *anilist.Client, media anilist.Media) tea.Cmd {
+	return func() tea.Msg {
+		offset, first, err := client.EpisodeOffset(context.Background(), media)
+		return episodeOffsetMsg{animeID: media.ID, offset: offset, baseTitles: collectTitles(first), err: err}
+	}
+}
+
+// relatedEntries lists the anime related to media, by relation type, then its
+// recommendations. Manga and anime without an ID are left out.
+func relatedEntries(media anilist.Media) []relatedEntry {
+	var entries []relatedEntry
+	seen := map[int]bool{media.ID: true}
+	add := func(kind string, n anilist.Media) {
+		if n.ID == 0 || seen[n.ID] || (n.Type != "" && n.Type != "ANIME") {
+			return
+		}
+		seen[n.ID] = true
+		var info []string
+		if n.Format != "" {
+			info = append(info, strings.ReplaceAll(n.Format, "_", " "))
+		}
+		if n.SeasonYear > 0 {
+			info = append(info, fmt.Sprint(n.SeasonYear))
+		}
+		entries = append(entries, relatedEntry{
+			animeID: n.ID,
+			kind:    kind,
+			title:   n.Title.DisplayTitle(),
+			info:    strings.Join(info, " · "),
+		})
+	}
+
+	for _, t := range relatedTypes {
+		for _, e := range media.Relations.Edges {
+			if e.RelationType == t {
+				add(formatSource(t), e.Node)
+			}
+		}
+	}
+	for _, rec := range media.Recommendations.Nodes {
+		if rec.MediaRecommendation != nil {
+			add("Recommended", *rec.MediaRecommendation)
+		}
+	}
+	return entries
+}
+
 // availableEpisodes returns how many episodes should be selectable for torrent search.
-// For releasing shows, limit to already-aired episodes.
+// For releasing and upcoming shows, limit to already-aired episodes.
 func availableEpisodes(media anilist.Media) int {
-	if media.Status == "RELEASING" && media.NextAiringEpisode != nil {
+	if media.NextAiringEpisode != nil {
 		aired := media.NextAiringEpisode.Episode - 1
 		if aired < 0 {
 			return 0
@@ -365,6 +901,16 @@ func availableEpisodes(media anilist.Media) int {
 	return 0
 }
 
+// listedEpisodes returns how many episodes the selector lists: every known
+// episode, or the aired ones and the next one when the total is unknown.
+func listedEpisodes(media anilist.Media) int {
+	n := max(availableEpisodes(media), media.Episodes)
+	if next := media.NextAiringEpisode; next != nil {
+		n = max(n, next.Episode)
+	}
+	return n
+}
+
 func formatTimeUntil(seconds int) string {
 	if seconds <= 0 {
 		return "soon"
@@ -449,20 +995,80 @@ func formatSource(s string) string {
 	return strings.Join(parts, " ")
 }
 
-// collectTitles returns non-empty Latin title variants for filtering.
+// collectTitles returns non-empty Latin title variants for filtering: the
+// English and Romaji titles and synonyms. When none of them names a season
+// but the anime continues a prequel, "<prequel> Season N" is added as well,
+// since many releases are named that way.
 // Native (CJK) titles are excluded because the Latin tokenizer splits them
 // into individual characters, causing spurious matches.
-func collectTitles(t anilist.Title) []string {
+func collectTitles(media anilist.Media) []string {
 	var titles []string
-	if t.English != "" {
-		titles = append(titles, t.English)
+	seen := make(map[string]bool)
+	add := func(t string) {
+		t = strings.TrimSpace(t)
+		key := strings.ToLower(t)
+		if t == "" || seen[key] || !isLatinTitle(t) {
+			return
+		}
+		seen[key] = true
+		titles = append(titles, t)
+	}
+
+	add(media.Title.English)
+	add(media.Title.Romaji)
+	for _, syn := range media.Synonyms {
+		add(syn)
+	}
+
+	for _, t := range titles {
+		if season, part := nyaa.SeasonOf(t); season > 0 || part > 0 {
+			return titles
+		}
+	}
+	prequel, ok := media.Prequel()
+	if !ok {
+		return titles
 	}
-	if t.Romaji != "" {
-		titles = append(titles, t.Romaji)
+	season := 2
+	for _, t := range append([]string{prequel.Title.English, prequel.Title.Romaji}, prequel.Synonyms...) {
+		if s, _ := nyaa.SeasonOf(t); s > 0 {
+			season = s + 1
+			break
+		}
+	}
+	for _, t := range []string{prequel.Title.English, prequel.Title.Romaji} {
+		if t != "" {
+			add(fmt.Sprintf("%s Season %d", nyaa.BaseTitle(t), season))
+		}
 	}
 	return titles
 }
 
+// isLatinTitle reports whether every letter in t is Latin script.
+func isLatinTitle(t string) bool {
+	for _, r := range t {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// truncate shortens s to at most width cells, ending it with "…" when cut.
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+	r := []rune(s)
+	for len(r) > 0 && lipgloss.Width(string(r))+1 > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
+
 // wordWrap wraps text to the given width at word boundaries.
 func wordWrap(s string, width int) string {
 	if width <= 0 {
@@ -0,0 +1,179 @@
+package views
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rayanxn/ani-tui/internal/anilist"
+	"github.com/rayanxn/ani-tui/internal/ui"
+)
+
+// listPicker is the detail view's panel for putting the anime on the
+// viewer's custom lists. It replaces the episode selector while open.
+type listPicker struct {
+	open    bool
+	names   []string // the viewer's custom lists
+	cursor  int
+	loading bool
+	err     error
+}
+
+// customListsMsg carries the names of the viewer's custom lists.
+type customListsMsg struct {
+	animeID int
+	names   []string
+	err     error
+}
+
+// customListsSavedMsg reports a change to the anime's custom lists. prev is
+// the list entry from before, restored if the change failed.
+type customListsSavedMsg struct {
+	animeID int
+	prev    *anilist.MediaList
+	err     error
+}
+
+// listsOpen reports whether the custom list picker is showing.
+func (m DetailModel) listsOpen() bool {
+	return m.lists.open
+}
+
+// openLists shows the custom list picker, loading the list names.
+func (m DetailModel) openLists() (DetailModel, tea.Cmd) {
+	if !m.client.Authenticated() {
+		m.notice = "Log in to AniList to use custom lists"
+		return m, nil
+	}
+	m.notice = ""
+	m.lists = listPicker{open: true, loading: true, cursor: m.lists.cursor}
+	return m, fetchCustomListsCmd(m.client, m.animeID)
+}
+
+// updateLists handles keys while the custom list picker is open.
+func (m DetailModel) updateLists(key string) (DetailModel, tea.Cmd) {
+	switch key {
+	case "esc", "L":
+		m.lists.open = false
+	case "j", "down":
+		m.lists.cursor = min(m.lists.cursor+1, max(len(m.lists.names)-1, 0))
+	case "k", "up":
+		m.lists.cursor = max(m.lists.cursor-1, 0)
+	case "enter", " ":
+		if m.lists.loading || m.lists.cursor >= len(m.lists.names) {
+			return m, nil
+		}
+		return m.toggleList(m.lists.names[m.lists.cursor])
+	}
+	return m, nil
+}
+
+// toggleList adds the anime to the named custom list or removes it, showing
+// the change straight away. An anime not on the viewer's list yet is added
+// as planning.
+func (m DetailModel) toggleList(name string) (DetailModel, tea.Cmd) {
+	prev := m.media.MediaListEntry
+	entry := anilist.MediaList{Status: "PLANNING"}
+	status := "PLANNING"
+	if prev != nil {
+		entry = *prev
+		status = ""
+	}
+	entry.CustomLists = maps.Clone(entry.CustomLists)
+	if entry.CustomLists == nil {
+		entry.CustomLists = make(map[string]bool)
+	}
+	entry.CustomLists[name] = !entry.CustomLists[name]
+	m.media.MediaListEntry = &entry
+	m.lists.err = nil
+
+	var on []string
+	for _, n := range m.lists.names {
+		if entry.CustomLists[n] {
+			on = append(on, n)
+		}
+	}
+	return m, saveCustomListsCmd(m.client, m.animeID, on, status, prev)
+}
+
+// renderLists renders the custom list picker with a mark on each list the
+// anime is on.
+func (m DetailModel) renderLists(width, height int) string {
+	subtleStyle := lipgloss.NewStyle().Foreground(ui.ColorSubtle)
+	header := lipgloss.NewStyle().Bold(true).Foreground(ui.ColorPrimary).Padding(0, 1).Render("Custom Lists")
+	lines := []string{header, ui.DimDivider(width)}
+
+	entry := m.media.MediaListEntry
+	switch {
+	case m.lists.loading:
+		lines = append(lines, subtleStyle.Render("  Loading..."))
+	case len(m.lists.names) == 0 && m.lists.err == nil:
+		lines = append(lines, subtleStyle.Render("  You have no custom lists."), subtleStyle.Render("  Create them in your AniList settings."))
+	default:
+		for i, name := range m.lists.names {
+			mark := "[ ]"
+			if entry != nil && entry.CustomLists[name] {
+				mark = "[x]"
+			}
+			row := mark + " " + truncate(name, max(1, width-8))
+			if i == m.lists.cursor {
+				lines = append(lines, ui.SelectedItemStyle.Render("▸ "+row))
+			} else {
+				lines = append(lines, "  "+lipgloss.NewStyle().Foreground(ui.ColorText).Render(row))
+			}
+		}
+		if entry == nil {
+			lines = append(lines, "", subtleStyle.Render("  Adding also puts it on Planning"))
+		}
+	}
+	if m.lists.err != nil {
+		lines = append(lines, "", ui.RenderError(m.lists.err.Error()))
+	}
+	lines = append(lines, "", subtleStyle.Render("  enter toggle · esc close"))
+
+	return lipgloss.NewStyle().Width(width).Height(height).Render(strings.Join(lines, "\n"))
+}
+
+// addListNames records the loaded custom list names, keeping the ones the
+// anime is on even if the viewer's settings no longer name them.
+func (m DetailModel) addListNames(msg customListsMsg) DetailModel {
+	if msg.animeID != m.animeID {
+		return m
+	}
+	m.lists.loading = false
+	if msg.err != nil {
+		m.lists.err = msg.err
+		return m
+	}
+	names := slices.Clone(msg.names)
+	if e := m.media.MediaListEntry; e != nil {
+		for _, name := range slices.Sorted(maps.Keys(e.CustomLists)) {
+			if e.CustomLists[name] && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	m.lists.names = names
+	m.lists.cursor = min(m.lists.cursor, max(len(names)-1, 0))
+	return m
+}
+
+// fetchCustomListsCmd loads the names of the viewer's custom lists.
+func fetchCustomListsCmd(client *anilist.Client, animeID int) tea.Cmd {
+	return func() tea.Msg {
+		names, err := client.GetCustomLists(context.Background())
+		return customListsMsg{animeID: animeID, names: names, err: err}
+	}
+}
+
+// saveCustomListsCmd puts the anime on exactly the given custom lists.
+func saveCustomListsCmd(client *anilist.Client, animeID int, lists []string, status string, prev *anilist.MediaList) tea.Cmd {
+	return func() tea.Msg {
+		err := client.SaveCustomLists(context.Background(), animeID, lists, status)
+		return customListsSavedMsg{animeID: animeID, prev: prev, err: err}
+	}
+}
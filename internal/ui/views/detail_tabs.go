@@ -0,0 +1,287 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rayanxn/ani-tui/internal/anilist"
+	"github.com/rayanxn/ani-tui/internal/ui"
+)
+
+// detailTab is a page of the detail view's left panel.
+type detailTab int
+
+const (
+	tabOverview detailTab = iota
+	tabCharacters
+	tabStaff
+	tabStats
+)
+
+var detailTabNames = []string{"Overview", "Characters", "Staff", "Stats"}
+
+// voiceLanguages are the voice actor languages the Characters tab cycles
+// through, as AniList StaffLanguage values.
+var voiceLanguages = []string{"JAPANESE", "ENGLISH", "KOREAN", "SPANISH", "PORTUGUESE", "FRENCH", "GERMAN", "ITALIAN"}
+
+// creditRow is one line of the Characters or Staff tab.
+type creditRow struct {
+	name  string
+	role  string
+	extra string // voice actors, for characters
+}
+
+// creditList is the paginated content of the Characters or Staff tab. Pages
+// are loaded when the tab opens and whenever the cursor reaches the end.
+type creditList struct {
+	rows    []creditRow
+	page    int // last page loaded, 0 before the first
+	more    bool
+	cursor  int
+	loading bool
+	err     error
+}
+
+// creditsMsg carries one page of characters or staff.
+type creditsMsg struct {
+	animeID  int
+	tab      detailTab
+	language string // for characters; pages in another language are stale
+	page     int
+	rows     []creditRow
+	more     bool
+	err      error
+}
+
+// credits returns the list shown on tab, Characters or Staff.
+func (m *DetailModel) credits(tab detailTab) *creditList {
+	if tab == tabCharacters {
+		return &m.characters
+	}
+	return &m.staff
+}
+
+// switchTab moves delta tabs along, wrapping around, and loads the first
+// page of a list tab opened for the first time.
+func (m DetailModel) switchTab(delta int) (DetailModel, tea.Cmd) {
+	n := len(detailTabNames)
+	m.tab = detailTab((int(m.tab) + delta + n) % n)
+	if m.tab == tabCharacters || m.tab == tabStaff {
+		if m.credits(m.tab).page == 0 {
+			return m, m.loadCredits(m.tab)
+		}
+	}
+	return m, nil
+}
+
+// loadCredits starts loading the next page of tab's list, unless a page is
+// already loading or there are no more.
+func (m *DetailModel) loadCredits(tab detailTab) tea.Cmd {
+	l := m.credits(tab)
+	if l.loading || (l.page > 0 && !l.more) {
+		return nil
+	}
+	l.loading = true
+	l.err = nil
+	return m.fetchCredits(tab)
+}
+
+// fetchCredits returns the command that loads the next page of tab's list.
+func (m DetailModel) fetchCredits(tab detailTab) tea.Cmd {
+	next := m.credits(tab).page + 1
+	if tab == tabCharacters {
+		return fetchCharactersCmd(m.client, m.animeID, next, voiceLanguages[m.language])
+	}
+	return fetchStaffCmd(m.client, m.animeID, next)
+}
+
+// updateTab handles keys on the Characters, Staff and Stats tabs.
+func (m DetailModel) updateTab(key string) (DetailModel, tea.Cmd) {
+	if m.tab != tabCharacters && m.tab != tabStaff {
+		return m, nil
+	}
+	l := m.credits(m.tab)
+	switch key {
+	case "j", "down":
+		if l.cursor < len(l.rows)-1 {
+			l.cursor++
+		}
+		if l.cursor >= len(l.rows)-1 {
+			return m, m.loadCredits(m.tab)
+		}
+	case "k", "up":
+		l.cursor = max(l.cursor-1, 0)
+	case "g":
+		l.cursor = 0
+	case "G":
+		l.cursor = max(len(l.rows)-1, 0)
+		return m, m.loadCredits(m.tab)
+	case "v":
+		if m.tab == tabCharacters {
+			m.language = (m.language + 1) % len(voiceLanguages)
+			m.characters = creditList{}
+			return m, m.loadCredits(tabCharacters)
+		}
+	}
+	return m, nil
+}
+
+// addCredits appends a loaded page to its list.
+func (m DetailModel) addCredits(msg creditsMsg) DetailModel {
+	if msg.animeID != m.animeID {
+		return m
+	}
+	if msg.tab == tabCharacters && msg.language != voiceLanguages[m.language] {
+		return m
+	}
+	l := m.credits(msg.tab)
+	l.loading = false
+	if msg.err != nil {
+		l.err = msg.err
+		return m
+	}
+	l.rows = append(l.rows, msg.rows...)
+	l.page = msg.page
+	l.more = msg.more
+	return m
+}
+
+// renderTabBar draws the tab names with the current one highlighted.
+func (m DetailModel) renderTabBar() string {
+	active := lipgloss.NewStyle().Bold(true).Foreground(ui.ColorPrimary).Underline(true)
+	inactive := lipgloss.NewStyle().Foreground(ui.ColorSubtle)
+	names := make([]string, len(detailTabNames))
+	for i, name := range detailTabNames {
+		if detailTab(i) == m.tab {
+			names[i] = active.Render(name)
+		} else {
+			names[i] = inactive.Render(name)
+		}
+	}
+	return strings.Join(names, "   ") + inactive.Render("   [ ] switch")
+}
+
+// renderTab renders the current tab of the left panel. cursorLine is the
+// line of the selected row, or -1 when nothing is selected.
+func (m DetailModel) renderTab(width int) (content string, cursorLine int) {
+	switch m.tab {
+	case tabCharacters, tabStaff:
+		return m.renderCredits(width)
+	case tabStats:
+		return m.renderStats(width), -1
+	}
+	return m.renderMetadata(width)
+}
+
+// renderCredits renders the Characters or Staff list in columns.
+func (m DetailModel) renderCredits(width int) (string, int) {
+	subtleStyle := lipgloss.NewStyle().Foreground(ui.ColorSubtle)
+	l := m.credits(m.tab)
+
+	var lines []string
+	if m.tab == tabCharacters {
+		lines = append(lines,
+			subtleStyle.Render("Voice actors: "+formatSource(voiceLanguages[m.language])+"  (v to change)"), "")
+	}
+
+	nameWidth := min(28, width/3)
+	roleWidth := 12
+	cursorLine := -1
+	for i, r := range l.rows {
+		cols := fmt.Sprintf("%-*s ", nameWidth, truncate(r.name, nameWidth))
+		if m.tab == tabCharacters {
+			cols += fmt.Sprintf("%-*s %s", roleWidth, r.role, truncate(r.extra, max(1, width-nameWidth-roleWidth-4)))
+		} else {
+			cols += truncate(r.role, max(1, width-nameWidth-3))
+		}
+		if i == l.cursor {
+			cursorLine = len(lines)
+			lines = append(lines, ui.SelectedItemStyle.Render("▸ "+cols))
+		} else {
+			lines = append(lines, "  "+lipgloss.NewStyle().Foreground(ui.ColorText).Render(cols))
+		}
+	}
+
+	switch {
+	case l.loading:
+		lines = append(lines, subtleStyle.Render("  Loading..."))
+	case l.err != nil:
+		lines = append(lines, ui.RenderError(l.err.Error()))
+	case len(l.rows) == 0:
+		lines = append(lines, subtleStyle.Render("  Nobody listed"))
+	}
+	return strings.Join(lines, "\n"), cursorLine
+}
+
+// renderStats charts how users score and list the anime.
+func (m DetailModel) renderStats(width int) string {
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.ColorPrimary)
+	stats := m.media.Stats
+	if len(stats.ScoreDistribution) == 0 && len(stats.StatusDistribution) == 0 {
+		return lipgloss.NewStyle().Foreground(ui.ColorSubtle).Render("No statistics yet")
+	}
+
+	var sections []string
+	if len(stats.ScoreDistribution) > 0 {
+		scores := slices.Clone(stats.ScoreDistribution)
+		slices.SortFunc(scores, func(a, b anilist.ScoreDistribution) int { return a.Score - b.Score })
+		bars := make([]ui.Bar, len(scores))
+		for i, s := range scores {
+			bars[i] = ui.Bar{Label: fmt.Sprint(s.Score), Value: s.Amount}
+		}
+		sections = append(sections, labelStyle.Render("Score Distribution")+"\n"+ui.RenderBarChart(bars, width))
+	}
+	if len(stats.StatusDistribution) > 0 {
+		bars := make([]ui.Bar, len(stats.StatusDistribution))
+		for i, s := range stats.StatusDistribution {
+			bars[i] = ui.Bar{Label: listStatusLabel(s.Status), Value: s.Amount}
+		}
+		sections = append(sections, labelStyle.Render("Status Distribution")+"\n"+ui.RenderBarChart(bars, width))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// listStatusLabel names a list status the way the library tabs do.
+func listStatusLabel(status string) string {
+	if label, ok := statusLabels[status]; ok {
+		return label
+	}
+	return formatSource(status)
+}
+
+// fetchCharactersCmd loads a page of characters with voice actors in language.
+func fetchCharactersCmd(client *anilist.Client, id, page int, language string) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := client.GetCharacters(context.Background(), id, page, language)
+		msg := creditsMsg{animeID: id, tab: tabCharacters, language: language, page: page, more: conn.PageInfo.HasNextPage, err: err}
+		for _, e := range conn.Edges {
+			var actors []string
+			for _, va := range e.VoiceActors {
+				actors = append(actors, va.Name.Full)
+			}
+			msg.rows = append(msg.rows, creditRow{
+				name:  e.Node.Name.Full,
+				role:  formatSource(e.Role),
+				extra: strings.Join(actors, ", "),
+			})
+		}
+		return msg
+	}
+}
+
+// fetchStaffCmd loads a page of staff.
+func fetchStaffCmd(client *anilist.Client, id, page int) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := client.GetStaff(context.Background(), id, page)
+		msg := creditsMsg{animeID: id, tab: tabStaff, page: page, more: conn.PageInfo.HasNextPage, err: err}
+		for _, e := range conn.Edges {
+			msg.rows = append(msg.rows, creditRow{name: e.Node.Name.Full, role: e.Role})
+		}
+		return msg
+	}
+}
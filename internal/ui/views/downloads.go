@@ -0,0 +1,211 @@
+package views
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rayanxn/ani-tui/internal/downloads"
+	"github.com/rayanxn/ani-tui/internal/torrent"
+	"github.com/rayanxn/ani-tui/internal/ui"
+)
+
+type downloadRemovedMsg struct {
+	err error
+}
+
+// DownloadListItem wraps a downloads.Entry for bubbles/list rendering.
+type DownloadListItem struct {
+	entry downloads.Entry
+}
+
+func (i DownloadListItem) Title() string {
+	return fmt.Sprintf("%s - Episode %d", i.entry.Title, i.entry.Episode)
+}
+func (i DownloadListItem) FilterValue() string { return i.entry.Title }
+func (i DownloadListItem) Description() string {
+	state := "Partial"
+	if i.entry.Complete {
+		state = "Complete"
+	}
+	parts := []string{state}
+	if i.entry.Size > 0 {
+		parts = append(parts, torrent.FormatBytes(i.entry.Size))
+	}
+	if !i.entry.UpdatedAt.IsZero() {
+		parts = append(parts, i.entry.UpdatedAt.Format("2006-01-02"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// DownloadsModel lists stored episodes and lets the user play or delete them.
+type DownloadsModel struct {
+	index         *downloads.Index
+	client        *torrent.Client
+	list          list.Model
+	confirmDelete bool // true after the first "x" press on an item
+	err           error
+}
+
+// NewDownloadsModel creates a downloads view backed by the given index.
+// client, if set, releases a file's torrent before the file is deleted.
+func NewDownloadsModel(index *downloads.Index, client *torrent.Client) DownloadsModel {
+	base := list.NewDefaultDelegate()
+	base.Styles.SelectedTitle = base.Styles.SelectedTitle.
+		Foreground(ui.ColorPrimary).
+		BorderLeftForeground(ui.ColorPrimary)
+	base.Styles.SelectedDesc = base.Styles.SelectedDesc.
+		Foreground(ui.ColorSecondary).
+		BorderLeftForeground(ui.ColorPrimary)
+	l := list.New(nil, base, 0, 0)
+	l.Title = "Downloads"
+	l.SetShowHelp(false)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.Styles.Title = ui.TitleStyle
+
+	m := DownloadsModel{index: index, client: client, list: l}
+	m.reload()
+	return m
+}
+
+func (m DownloadsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m DownloadsModel) Update(msg tea.Msg) (DownloadsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height-6)
+		return m, nil
+
+	case downloadRemovedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		m.reload()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.err != nil {
+			if msg.String() == "esc" || msg.String() == "enter" {
+				m.err = nil
+				return m, nil
+			}
+		}
+
+		if msg.String() != "x" {
+			m.confirmDelete = false
+		}
+
+		switch msg.String() {
+		case "enter":
+			item, ok := m.list.SelectedItem().(DownloadListItem)
+			if !ok {
+				return m, nil
+			}
+			e := item.entry
+			source := e.Path
+			if !e.Complete {
+				if e.InfoHash == "" {
+					m.err = fmt.Errorf("download is incomplete and has no info hash to resume from")
+					return m, nil
+				}
+				source = "magnet:?xt=urn:btih:" + e.InfoHash
+			}
+			return m, func() tea.Msg {
+				return NavigateToPlayerMsg{
+					Source:     source,
+					AnimeID:    e.MediaID,
+					AnimeTitle: e.Title,
+					Episode:    e.Episode,
+				}
+			}
+		case "x":
+			item, ok := m.list.SelectedItem().(DownloadListItem)
+			if !ok {
+				return m, nil
+			}
+			if !m.confirmDelete {
+				m.confirmDelete = true
+				return m, nil
+			}
+			m.confirmDelete = false
+			return m, removeDownloadCmd(m.index, m.client, item.entry)
+		case "r":
+			m.reload()
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// View renders the downloads view within the given dimensions.
+func (m DownloadsModel) View(width, height int) string {
+	var footer string
+	if m.confirmDelete {
+		footer = ui.ErrorStyle.Render("Press x again to delete this file from disk")
+	}
+
+	listHeight := height - lipgloss.Height(footer)
+	if listHeight < 0 {
+		listHeight = 0
+	}
+	m.list.SetSize(width, listHeight)
+
+	var body string
+	switch {
+	case m.err != nil:
+		body = lipgloss.NewStyle().Padding(1, 0).Render(ui.RenderError(m.err.Error()))
+	case len(m.list.Items()) == 0:
+		body = lipgloss.NewStyle().Padding(1, 2).Render(
+			ui.TitleStyle.Render("Downloads") + "\n" +
+				ui.HelpStyle.Render("No downloaded episodes yet"))
+	default:
+		body = m.list.View()
+	}
+
+	content := body
+	if footer != "" {
+		content += "\n" + footer
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Render(content)
+}
+
+// reload refreshes the list from the index.
+func (m *DownloadsModel) reload() {
+	entries := m.index.Entries()
+	items := make([]list.Item, len(entries))
+	for i, e := range entries {
+		items[i] = DownloadListItem{entry: e}
+	}
+	m.list.SetItems(items)
+}
+
+// removeDownloadCmd deletes a downloaded file. Its torrent is dropped first
+// so it stops seeding; a torrent still streaming or queued keeps the file.
+func removeDownloadCmd(index *downloads.Index, client *torrent.Client, e downloads.Entry) tea.Cmd {
+	return func() tea.Msg {
+		var root string
+		if client != nil {
+			if e.InfoHash != "" {
+				if err := client.DropTorrent(e.InfoHash); err != nil {
+					return downloadRemovedMsg{err: fmt.Errorf("cannot delete %s: %w", filepath.Base(e.Path), err)}
+				}
+			}
+			root = client.DownloadDir()
+		}
+		return downloadRemovedMsg{err: index.Remove(e.Path, root)}
+	}
+}
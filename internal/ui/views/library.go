@@ -1,9 +1,12 @@
 package views
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -14,38 +17,156 @@ import (
 	"github.com/rayanxn/ani-tui/internal/ui"
 )
 
+// libraryFetchedMsg carries the user's lists. Stale lists come from the
+// cache and are being refreshed.
 type libraryFetchedMsg struct {
-	lists map[string][]anilist.MediaList
-	err   error
+	tabs    []libraryTab
+	err     error
+	stale   bool
+	refresh bool // fetched to replace lists already shown
 }
 
+// progressBarWidth is how many cells a library row's progress bar takes.
+const progressBarWidth = 12
+
 // LibraryListItem wraps an anilist.MediaList for bubbles/list rendering.
 type LibraryListItem struct {
-	entry anilist.MediaList
+	entry      anilist.MediaList
+	showStatus bool // set while filtering across every status
 }
 
-func (i LibraryListItem) Title() string       { return i.entry.Media.Title.DisplayTitle() }
-func (i LibraryListItem) FilterValue() string  { return i.entry.Media.Title.DisplayTitle() }
+func (i LibraryListItem) Title() string {
+	title := i.entry.Media.Title.DisplayTitle()
+	if n := newEpisodes(i.entry); n > 0 {
+		title += fmt.Sprintf("  ● %d new", n)
+	}
+	return title
+}
+
+func (i LibraryListItem) FilterValue() string { return i.entry.Media.Title.DisplayTitle() }
 func (i LibraryListItem) Description() string {
 	parts := []string{fmt.Sprintf("Progress: %d", i.entry.Progress)}
-	if i.entry.Media.Episodes > 0 {
-		parts[0] = fmt.Sprintf("Progress: %d/%d", i.entry.Progress, i.entry.Media.Episodes)
+	if total := entryTotal(i.entry); total > 0 {
+		parts[0] = fmt.Sprintf("%s %d/%d", progressBar(i.entry.Progress, total, progressBarWidth), i.entry.Progress, total)
 	}
 	if i.entry.Score > 0 {
 		parts = append(parts, fmt.Sprintf("Score: %d", i.entry.Score))
 	}
+	if next := i.entry.Media.NextAiringEpisode; next != nil {
+		// airingAt rather than timeUntilAiring, which is stale once cached.
+		until := int(time.Until(time.Unix(next.AiringAt, 0)).Seconds())
+		parts = append(parts, fmt.Sprintf("Ep %d in %s", next.Episode, formatTimeUntil(until)))
+	}
+	if i.showStatus {
+		parts = append(parts, listStatusLabel(i.entry.Status))
+	}
 	return strings.Join(parts, " · ")
 }
 
-var tabLabels = []string{"Watching", "Completed", "Planning", "Dropped", "Paused"}
-var tabStatuses = []string{"CURRENT", "COMPLETED", "PLANNING", "DROPPED", "PAUSED"}
+// entryTotal returns the episode count a library row's progress is measured
+// against: every episode, or the aired ones while the total is unknown.
+func entryTotal(e anilist.MediaList) int {
+	if e.Media.Episodes > 0 {
+		return e.Media.Episodes
+	}
+	return availableEpisodes(e.Media)
+}
 
-// LibraryModel displays the user's anime library with tab-filtered categories.
+// newEpisodes returns how many aired episodes are left to watch on an
+// airing show being watched, or zero.
+func newEpisodes(e anilist.MediaList) int {
+	if e.Media.NextAiringEpisode == nil || (e.Status != "CURRENT" && e.Status != "REPEATING") {
+		return 0
+	}
+	return max(0, availableEpisodes(e.Media)-e.Progress)
+}
+
+// progressBar draws done out of total as width cells of "█" and "░".
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(width, max(0, done)*width/total)
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// librarySort is the order library rows are listed in.
+type librarySort int
+
+const (
+	sortTitle librarySort = iota
+	sortScore
+	sortProgress
+	sortUpdated
+	sortAiring
+)
+
+var librarySortLabels = []string{"title", "score", "progress", "updated", "next airing"}
+
+// next returns the sort after s, wrapping around.
+func (s librarySort) next() librarySort {
+	return (s + 1) % librarySort(len(librarySortLabels))
+}
+
+// sortEntries returns a copy of entries in order s. Ties are broken by title.
+func sortEntries(entries []anilist.MediaList, s librarySort) []anilist.MediaList {
+	out := slices.Clone(entries)
+	byTitle := func(a, b anilist.MediaList) int {
+		return strings.Compare(strings.ToLower(a.Media.Title.DisplayTitle()), strings.ToLower(b.Media.Title.DisplayTitle()))
+	}
+	slices.SortStableFunc(out, func(a, b anilist.MediaList) int {
+		var c int
+		switch s {
+		case sortScore:
+			c = cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(b.Media.AverageScore, a.Media.AverageScore))
+		case sortProgress:
+			c = cmp.Compare(b.Progress, a.Progress)
+		case sortUpdated:
+			c = cmp.Compare(b.UpdatedAt, a.UpdatedAt)
+		case sortAiring:
+			// Shows with a scheduled episode first, soonest first.
+			na, nb := a.Media.NextAiringEpisode, b.Media.NextAiringEpisode
+			switch {
+			case na != nil && nb != nil:
+				c = cmp.Compare(na.AiringAt, nb.AiringAt)
+			case na != nil:
+				c = -1
+			case nb != nil:
+				c = 1
+			}
+		}
+		return cmp.Or(c, byTitle(a, b))
+	})
+	return out
+}
+
+// statusLabels names list statuses the way AniList does.
+var statusLabels = map[string]string{
+	"CURRENT":   "Watching",
+	"REPEATING": "Rewatching",
+	"COMPLETED": "Completed",
+	"PLANNING":  "Planning",
+	"DROPPED":   "Dropped",
+	"PAUSED":    "Paused",
+}
+
+// libraryTab is one list returned by AniList: a status, a split of one such
+// as "Completed Movie", or a custom list.
+type libraryTab struct {
+	label   string
+	custom  bool
+	entries []anilist.MediaList
+}
+
+// LibraryModel displays the user's anime library with tab-filtered
+// categories. Filtering searches every category at once.
 type LibraryModel struct {
 	client    *anilist.Client
 	userID    int
 	activeTab int
-	lists     map[string][]anilist.MediaList
+	sort      librarySort
+	global    bool         // the list holds every category's entries for filtering
+	tabs      []libraryTab // in AniList's order; nil until loaded
 	list      list.Model
 	spinner   spinner.Model
 	loading   bool
@@ -84,7 +205,7 @@ func NewLibraryModel(client *anilist.Client, userID int) LibraryModel {
 func (m LibraryModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
-		fetchLibraryCmd(m.client, m.userID),
+		fetchLibraryCmd(m.client, m.userID, false),
 	)
 }
 
@@ -97,11 +218,37 @@ func (m LibraryModel) Update(msg tea.Msg) (LibraryModel, tea.Cmd) {
 	case libraryFetchedMsg:
 		m.loading = false
 		if msg.err != nil {
-			m.err = msg.err
+			// A failed refresh keeps the cached lists on screen.
+			if !msg.refresh || m.tabs == nil {
+				m.err = msg.err
+			}
 			return m, nil
 		}
-		m.lists = msg.lists
-		m.updateListItems()
+		cursor := m.list.Index()
+		shown := m.tabs != nil
+		// Stay on the open tab, wherever it moved to.
+		if shown && m.activeTab < len(m.tabs) {
+			label := m.tabs[m.activeTab].label
+			m.activeTab = 0
+			for i, t := range msg.tabs {
+				if t.label == label {
+					m.activeTab = i
+				}
+			}
+		}
+		m.tabs = msg.tabs
+		m.activeTab = min(m.activeTab, max(len(m.tabs)-1, 0))
+		if m.global {
+			m.list.SetItems(m.allItems())
+		} else {
+			m.updateListItems()
+		}
+		if shown {
+			m.list.Select(cursor)
+		}
+		if msg.stale {
+			return m, fetchLibraryCmd(m.client, m.userID, true)
+		}
 		return m, nil
 
 	case spinner.TickMsg:
@@ -122,24 +269,40 @@ func (m LibraryModel) Update(msg tea.Msg) (LibraryModel, tea.Cmd) {
 
 		// Don't handle tab keys when the list is filtering
 		if m.list.FilterState() == list.Filtering {
-			var cmd tea.Cmd
-			m.list, cmd = m.list.Update(msg)
-			return m, cmd
+			return m.updateList(msg)
 		}
 
 		switch msg.String() {
 		case "tab":
-			m.activeTab = (m.activeTab + 1) % len(tabLabels)
-			m.updateListItems()
+			if len(m.tabs) > 0 {
+				m.activeTab = (m.activeTab + 1) % len(m.tabs)
+				m.updateListItems()
+			}
 			return m, nil
 		case "shift+tab":
-			m.activeTab = (m.activeTab - 1 + len(tabLabels)) % len(tabLabels)
-			m.updateListItems()
+			if len(m.tabs) > 0 {
+				m.activeTab = (m.activeTab - 1 + len(m.tabs)) % len(m.tabs)
+				m.updateListItems()
+			}
 			return m, nil
-		case "r":
+		case "s":
+			m.sort = m.sort.next()
+			if m.global {
+				m.list.SetItems(m.allItems())
+			} else {
+				m.updateListItems()
+			}
+			return m, nil
+		case "/":
+			// Filter across every category, not only the open one.
+			if !m.global {
+				m.global = true
+				m.list.SetItems(m.allItems())
+			}
+		case "r", "ctrl+r":
 			m.loading = true
 			m.err = nil
-			return m, tea.Batch(m.spinner.Tick, fetchLibraryCmd(m.client, m.userID))
+			return m, tea.Batch(m.spinner.Tick, fetchLibraryCmd(m.client, m.userID, true))
 		case "enter":
 			item, ok := m.list.SelectedItem().(LibraryListItem)
 			if !ok {
@@ -150,9 +313,7 @@ func (m LibraryModel) Update(msg tea.Msg) (LibraryModel, tea.Cmd) {
 			}
 		}
 
-		var cmd tea.Cmd
-		m.list, cmd = m.list.Update(msg)
-		return m, cmd
+		return m.updateList(msg)
 	}
 
 	var cmd tea.Cmd
@@ -178,7 +339,7 @@ func (m LibraryModel) View(width, height int) string {
 	case m.err != nil:
 		body = lipgloss.NewStyle().Padding(1, 0).Render(ui.RenderError(m.err.Error()))
 	case len(m.list.Items()) == 0:
-		body = ui.HelpStyle.Render("  No anime in this category")
+		body = ui.HelpStyle.Render("  No anime in this list")
 	default:
 		body = m.list.View()
 	}
@@ -187,30 +348,72 @@ func (m LibraryModel) View(width, height int) string {
 	return lipgloss.NewStyle().Width(width).Height(height).Render(content)
 }
 
+// updateList passes a key to the list, going back to the open category once
+// a filter is cleared.
+func (m LibraryModel) updateList(msg tea.KeyMsg) (LibraryModel, tea.Cmd) {
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	if m.global && m.list.FilterState() == list.Unfiltered {
+		m.global = false
+		m.updateListItems()
+	}
+	return m, cmd
+}
+
+// renderTabBar draws a tab per list. When they don't all fit, the bar
+// scrolls to keep the open tab in view, with arrows marking hidden tabs.
 func (m LibraryModel) renderTabBar(width int) string {
-	var tabs []string
-	for i, label := range tabLabels {
-		count := len(m.lists[tabStatuses[i]])
-		text := fmt.Sprintf("%s (%d)", label, count)
+	tabs := make([]string, len(m.tabs))
+	for i, t := range m.tabs {
+		text := fmt.Sprintf("%s (%d)", t.label, len(t.entries))
+		if t.custom {
+			text = "☰ " + text
+		}
 		if i == m.activeTab {
-			tabs = append(tabs, ui.ActiveTabStyle.Render(text))
+			tabs[i] = ui.ActiveTabStyle.Render(text)
 		} else {
-			tabs = append(tabs, ui.InactiveTabStyle.Render(text))
+			tabs[i] = ui.InactiveTabStyle.Render(text)
 		}
 	}
 
-	row := lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
-	gap := width - lipgloss.Width(row)
+	sortLabel := ui.InactiveTabStyle.Render("sort: " + librarySortLabels[m.sort])
+	more := func(arrow string) string { return ui.InactiveTabStyle.Render(arrow) }
+	avail := width - lipgloss.Width(sortLabel) - 2*lipgloss.Width(more("‹"))
+	first, last := 0, len(tabs)
+	for first < m.activeTab && lipgloss.Width(strings.Join(tabs[first:m.activeTab+1], "")) > avail {
+		first++
+	}
+	for last > first+1 && lipgloss.Width(strings.Join(tabs[first:last], "")) > avail {
+		last--
+	}
+	shown := tabs[first:last]
+	if first > 0 {
+		shown = append([]string{more("‹")}, shown...)
+	}
+	if last < len(tabs) {
+		shown = append(shown, more("›"))
+	}
+
+	row := lipgloss.JoinHorizontal(lipgloss.Top, shown...)
+	gap := width - lipgloss.Width(row) - lipgloss.Width(sortLabel)
 	if gap > 0 {
 		fill := lipgloss.NewStyle().Background(ui.ColorHeaderBg).Render(strings.Repeat(" ", gap))
-		row += fill
+		row += fill + sortLabel
 	}
 	return row
 }
 
+// updateListItems lists the open category in the current order, leaving
+// any filter.
 func (m *LibraryModel) updateListItems() {
-	status := tabStatuses[m.activeTab]
-	entries := m.lists[status]
+	if m.global {
+		m.global = false
+		m.list.ResetFilter()
+	}
+	var entries []anilist.MediaList
+	if m.activeTab < len(m.tabs) {
+		entries = sortEntries(m.tabs[m.activeTab].entries, m.sort)
+	}
 	items := make([]list.Item, len(entries))
 	for i, e := range entries {
 		items[i] = LibraryListItem{entry: e}
@@ -219,17 +422,55 @@ func (m *LibraryModel) updateListItems() {
 	m.list.ResetSelected()
 }
 
-func fetchLibraryCmd(client *anilist.Client, userID int) tea.Cmd {
+// allItems lists every entry once, in the current order, labelled with its
+// status. Entries on custom lists are also in a status list, unless hidden
+// from them.
+func (m LibraryModel) allItems() []list.Item {
+	var entries []anilist.MediaList
+	seen := make(map[int]bool)
+	for _, t := range m.tabs {
+		for _, e := range t.entries {
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+	entries = sortEntries(entries, m.sort)
+	items := make([]list.Item, len(entries))
+	for i, e := range entries {
+		items[i] = LibraryListItem{entry: e, showStatus: true}
+	}
+	return items
+}
+
+// fetchLibraryCmd fetches the user's lists. Cached lists are returned first
+// unless refresh is set.
+func fetchLibraryCmd(client *anilist.Client, userID int, refresh bool) tea.Cmd {
 	return func() tea.Msg {
+		if !refresh {
+			if collection, fresh, ok := client.CachedUserList(userID); ok {
+				return libraryFetchedMsg{tabs: groupLists(collection), stale: !fresh}
+			}
+		}
 		collection, err := client.GetUserList(context.Background(), userID)
 		if err != nil {
-			return libraryFetchedMsg{err: err}
+			return libraryFetchedMsg{err: err, refresh: refresh}
 		}
+		return libraryFetchedMsg{tabs: groupLists(collection), refresh: refresh}
+	}
+}
 
-		lists := make(map[string][]anilist.MediaList)
-		for _, group := range collection.Lists {
-			lists[group.Status] = group.Entries
+// groupLists makes a tab of each list AniList returned, in its order. Empty
+// lists aren't returned.
+func groupLists(collection anilist.MediaListCollection) []libraryTab {
+	tabs := make([]libraryTab, 0, len(collection.Lists))
+	for _, group := range collection.Lists {
+		label := group.Name
+		if label == "" {
+			label = listStatusLabel(group.Status)
 		}
-		return libraryFetchedMsg{lists: lists}
+		tabs = append(tabs, libraryTab{label: label, custom: group.IsCustomList, entries: group.Entries})
 	}
+	return tabs
 }
@@ -3,6 +3,8 @@ package views
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -11,6 +13,9 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/rayanxn/ani-tui/internal/config"
+	"github.com/rayanxn/ani-tui/internal/downloads"
+	"github.com/rayanxn/ani-tui/internal/history"
+	"github.com/rayanxn/ani-tui/internal/nyaa"
 	"github.com/rayanxn/ani-tui/internal/player"
 	"github.com/rayanxn/ani-tui/internal/torrent"
 	"github.com/rayanxn/ani-tui/internal/ui"
@@ -21,41 +26,101 @@ type PlayerDoneMsg struct {
 	AnimeID    int
 	AnimeTitle string
 	Episode    int
+	// Request and Release are the search and release title that were
+	// played, for finding the next episode.
+	Request nyaa.SearchRequest
+	Release string
+	// Next is the following episode prefetched during playback, or nil.
+	Next *NavigateToPlayerMsg
+	// Binge asks the app to start the next episode straight away.
+	Binge bool
+	// LastEpisode is the last episode that has aired, or 0 if unknown.
+	LastEpisode int
 }
 
 // Messages for the player view lifecycle.
 type (
 	playerReadyMsg struct {
-		torrentClient *torrent.Client
+		streaming bool // true when a torrent is being streamed (not a local file)
 		session   *player.Session
 		err       error
 	}
-	statsTickMsg struct{}
-	mpvExitMsg   struct{ err error }
+	statsTickMsg        struct{}
+	downloadRecordedMsg struct{ err error }
+	positionRecordedMsg struct{ err error }
+	sessionRecordedMsg  struct{ err error }
+	mpvExitMsg          struct {
+		err error
+		eof bool // playback reached the end of the file
+	}
+	bingeTickMsg   struct{}
+	playbackPosMsg struct {
+		offset   time.Duration
+		duration time.Duration
+		err      error
+	}
+	prefetchReadyMsg struct {
+		next *NavigateToPlayerMsg
+		err  error
+	}
 )
 
 const streamTimeout = 2 * time.Minute
 
+const (
+	// prefetchPercent is how far into an episode playback must be before the
+	// next one is prefetched, unless a stream finished downloading first.
+	prefetchPercent = 75
+	// prefetchBytes is how much of the next episode is buffered ahead.
+	prefetchBytes = 32 * 1024 * 1024
+	// bingeCountdown is how many seconds the next episode is offered for
+	// before it starts.
+	bingeCountdown = 10
+	// maxWatchStep is the most a position poll may advance and still count
+	// as watch time; larger jumps are seeks.
+	maxWatchStep = 3 * time.Second
+)
+
 // PlayerModel manages torrent streaming and mpv playback.
 type PlayerModel struct {
-	magnetURI    string
-	animeTitle   string
-	episode      int
-	animeID      int
-	cfg          config.Config
-	streamCtx    context.Context
-	streamCancel context.CancelFunc
+	source        string
+	animeTitle    string
+	episode       int
+	animeID       int
+	request       nyaa.SearchRequest
+	release       string
+	lastEpisode   int // last aired episode, 0 if unknown
+	cfg           config.Config
+	downloads     *downloads.Index
+	history       *history.Store
+	resumeAt      time.Duration // where playback started, zero from the beginning
+	position      history.Position
+	sessions      *history.SessionLog
+	started       time.Time     // when mpv started playing
+	watched       time.Duration // playback time, excluding pauses and seeks
+	streaming     bool
+	streamCtx     context.Context
+	streamCancel  context.CancelFunc
 	torrentClient *torrent.Client
-	session      *player.Session
-	stats        torrent.Stats
-	spinner      spinner.Model
-	loading      bool
-	done         bool
-	err          error
+	torrentErr    error // why torrentClient is nil
+	session       *player.Session
+	stats         torrent.Stats
+	recorded      bool // true once the completed file is in the downloads index
+	prefetching   bool // true once the next episode search has started
+	prefetchErr   error
+	prefetchStop  context.CancelFunc
+	next          *NavigateToPlayerMsg
+	binge         bool
+	countdown     int // seconds left before binge mode starts the next episode
+	spinner       spinner.Model
+	loading       bool
+	done          bool
+	err           error
 }
 
-// NewPlayerModel creates a player view for the given magnet URI.
-func NewPlayerModel(magnetURI, animeTitle string, episode, animeID int, cfg config.Config) PlayerModel {
+// NewPlayerModel creates a player view for the message's source: a magnet
+// URI, a .torrent URL or path, or a local video file.
+func NewPlayerModel(msg NavigateToPlayerMsg, cfg config.Config, svc Services) PlayerModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = ui.SpinnerStyle
@@ -63,22 +128,37 @@ func NewPlayerModel(magnetURI, animeTitle string, episode, animeID int, cfg conf
 	ctx, cancel := context.WithTimeout(context.Background(), streamTimeout)
 
 	return PlayerModel{
-		magnetURI:    magnetURI,
-		animeTitle:   animeTitle,
-		episode:      episode,
-		animeID:      animeID,
-		cfg:          cfg,
-		streamCtx:    ctx,
-		streamCancel: cancel,
-		spinner:      s,
-		loading:      true,
+		source:        msg.Source,
+		animeTitle:    msg.AnimeTitle,
+		episode:       msg.Episode,
+		animeID:       msg.AnimeID,
+		request:       msg.Request,
+		release:       msg.Release,
+		lastEpisode:   msg.LastEpisode,
+		cfg:           cfg,
+		binge:         cfg.BingeMode,
+		downloads:     svc.Downloads,
+		history:       svc.History,
+		resumeAt:      svc.History.Resume(msg.AnimeID, msg.Episode),
+		position:      history.Position{MediaID: msg.AnimeID, Episode: msg.Episode},
+		sessions:      svc.Sessions,
+		torrentClient: svc.Torrents,
+		torrentErr:    svc.TorrentErr,
+		streamCtx:     ctx,
+		streamCancel:  cancel,
+		spinner:       s,
+		loading:       true,
 	}
 }
 
 func (m PlayerModel) Init() tea.Cmd {
+	if m.torrentClient == nil && torrent.ClassifySource(m.source) != torrent.SourceLocalFile {
+		err := m.torrentErr
+		return func() tea.Msg { return playerReadyMsg{err: fmt.Errorf("stream torrent: %w", err)} }
+	}
 	return tea.Batch(
 		m.spinner.Tick,
-		startStreamCmd(m.streamCtx, m.magnetURI, m.cfg),
+		startStreamCmd(m.streamCtx, m.source, m.cfg, m.torrentClient, m.resumeAt),
 	)
 }
 
@@ -91,33 +171,86 @@ func (m PlayerModel) Update(msg tea.Msg) (PlayerModel, tea.Cmd) {
 			return m, nil
 		}
 		m.loading = false
-		m.torrentClient = msg.torrentClient
+		m.streaming = msg.streaming
 		m.session = msg.session
-		return m, tea.Batch(
-			waitForMpvCmd(m.session),
-			statsTickCmd(),
-		)
+		m.started = time.Now()
+		cmds := []tea.Cmd{waitForMpvCmd(m.session), statsTickCmd()}
+		if m.streaming {
+			cmds = append(cmds, recordDownloadCmd(m.downloads, m.downloadEntry(false)))
+		}
+		return m, tea.Batch(cmds...)
 
 	case statsTickMsg:
-		if m.done || m.torrentClient == nil {
+		if m.done {
 			return m, nil
 		}
-		t := m.torrentClient.ActiveTorrent()
-		if t != nil {
-			m.stats = torrent.GetStats(t)
+		// The position is polled throughout so it can be saved for resuming.
+		cmds := []tea.Cmd{statsTickCmd(), playbackPosCmd(m.session)}
+		if m.streaming {
+			if t := m.torrentClient.ActiveTorrent(); t != nil {
+				m.stats = torrent.GetStats(t)
+			}
+			if f := m.torrentClient.ActiveFile(); f != nil && f.BytesCompleted() >= f.Length() {
+				if !m.recorded {
+					m.recorded = true
+					cmds = append(cmds, recordDownloadCmd(m.downloads, m.downloadEntry(true)))
+				}
+				// The finished stream leaves bandwidth for the next episode.
+				if m.canPrefetch() {
+					cmds = append(cmds, m.startPrefetch())
+				}
+			}
+		}
+		return m, tea.Batch(cmds...)
+
+	case playbackPosMsg:
+		// Position polling is best-effort; mpv may not have opened IPC yet.
+		if msg.err != nil || m.done {
+			return m, nil
 		}
-		return m, statsTickCmd()
+		if step := msg.offset - m.position.Offset; step > 0 && step <= maxWatchStep {
+			m.watched += step
+		}
+		m.position.Offset = msg.offset
+		m.position.Duration = msg.duration
+		if m.position.Percent() >= prefetchPercent && m.canPrefetch() {
+			return m, m.startPrefetch()
+		}
+		return m, nil
+
+	case prefetchReadyMsg:
+		m.next = msg.next
+		m.prefetchErr = msg.err
+		return m, nil
+
+	case downloadRecordedMsg, positionRecordedMsg, sessionRecordedMsg:
+		// Best-effort bookkeeping; playback is unaffected by index errors.
+		return m, nil
 
 	case mpvExitMsg:
 		m.done = true
+		if msg.eof {
+			m.position.Offset = m.position.Duration
+		}
+		record := tea.Batch(recordPositionCmd(m.history, m.position), recordSessionCmd(m.sessions, m.watchSession()))
+		if m.binge && msg.eof && m.hasNextEpisode() && m.request.PrimaryTitle != "" && m.animeID != 0 {
+			// Keep any prefetch running while the next episode is offered.
+			m.stopPlayback()
+			m.countdown = bingeCountdown
+			return m, tea.Batch(record, bingeTickCmd())
+		}
 		m.Cleanup()
-		return m, func() tea.Msg {
-			return PlayerDoneMsg{
-				AnimeID:    m.animeID,
-				AnimeTitle: m.animeTitle,
-				Episode:    m.episode,
-			}
+		return m, tea.Batch(record, m.doneCmd(false))
+
+	case bingeTickMsg:
+		if m.countdown == 0 {
+			return m, nil
 		}
+		m.countdown--
+		if m.countdown == 0 {
+			return m.finishBinge(true)
+		}
+		return m, bingeTickCmd()
 
 	case tea.KeyMsg:
 		if m.err != nil {
@@ -126,6 +259,23 @@ func (m PlayerModel) Update(msg tea.Msg) (PlayerModel, tea.Cmd) {
 				return m, nil
 			}
 		}
+		if m.countdown > 0 {
+			switch msg.String() {
+			case "enter":
+				return m.finishBinge(true)
+			case "esc", "c":
+				return m.finishBinge(false)
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "t":
+			if m.torrentClient != nil {
+				m.torrentClient.SetThrottled(!m.torrentClient.Throttled())
+			}
+		case "b":
+			m.binge = !m.binge
+		}
 		return m, nil
 
 	case spinner.TickMsg:
@@ -148,16 +298,24 @@ func (m PlayerModel) View(width, height int) string {
 
 	var body string
 	switch {
+	case m.countdown > 0:
+		body = m.renderCountdown(width)
 	case m.loading:
 		body = lipgloss.NewStyle().Padding(1, 2).Render(
 			m.spinner.View() + " Starting stream (esc to cancel)...",
 		)
 	case m.err != nil:
 		body = lipgloss.NewStyle().Padding(1, 0).Render(ui.RenderError(m.err.Error()))
+	case !m.streaming:
+		body = m.renderLocal()
 	default:
 		body = m.renderStats(width)
 	}
 
+	if m.resumeAt > 0 && !m.loading && m.err == nil && m.countdown == 0 {
+		title += "\n" + lipgloss.NewStyle().Padding(0, 2).Foreground(ui.ColorSubtle).
+			Render("Resumed from "+formatOffset(m.resumeAt))
+	}
 	content := title + "\n" + body
 	return lipgloss.NewStyle().Width(width).Height(height).Render(content)
 }
@@ -179,6 +337,10 @@ func (m PlayerModel) renderStats(width int) string {
 	pct := fmt.Sprintf("%.1f%%", progress*100)
 	downloaded := fmt.Sprintf("%s / %s", torrent.FormatBytes(m.stats.BytesCompleted), torrent.FormatBytes(m.stats.BytesTotal))
 	peers := fmt.Sprintf("Peers: %d  |  Seeders: %d", m.stats.Peers, m.stats.Seeders)
+	throttle := "Bandwidth: normal"
+	if m.torrentClient.Throttled() {
+		throttle = "Bandwidth: throttled to " + torrent.FormatSpeed(m.torrentClient.ThrottleRate())
+	}
 
 	lines := []string{
 		"",
@@ -189,56 +351,343 @@ func (m PlayerModel) renderStats(width int) string {
 				lipgloss.NewStyle().Foreground(ui.ColorSubtle).Render(downloaded),
 		),
 		lipgloss.NewStyle().Padding(0, 2).Foreground(ui.ColorSubtle).Render(peers),
+		lipgloss.NewStyle().Padding(0, 2).Foreground(ui.ColorSubtle).Render(throttle),
+		m.renderPrefetch(),
+		m.renderBinge(),
 		"",
-		ui.HelpStyle.Render("  mpv is playing in a separate window  |  esc back"),
+		ui.HelpStyle.Render("  mpv is playing in a separate window  |  t throttle  |  b binge  |  esc back"),
 	}
 
 	return strings.Join(lines, "\n")
 }
 
-// Cleanup releases torrent and player resources.
+// renderLocal shows the file being played when no torrent is involved.
+func (m PlayerModel) renderLocal() string {
+	lines := []string{
+		"",
+		lipgloss.NewStyle().Padding(0, 2).Foreground(ui.ColorSubtle).Render("Local file: " + filepath.Base(m.source)),
+		m.renderPrefetch(),
+		m.renderBinge(),
+		"",
+		ui.HelpStyle.Render("  mpv is playing in a separate window  |  b binge  |  esc back"),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderBinge shows whether binge mode is on.
+func (m PlayerModel) renderBinge() string {
+	state := "off"
+	switch {
+	case m.binge && !m.hasNextEpisode():
+		state = "on, last episode"
+	case m.binge:
+		state = "on"
+	}
+	return lipgloss.NewStyle().Padding(0, 2).Foreground(ui.ColorSubtle).Render("Binge mode: " + state)
+}
+
+// renderCountdown is the overlay offering the next episode after playback
+// reached the end.
+func (m PlayerModel) renderCountdown(width int) string {
+	next := fmt.Sprintf("Episode %d", m.episode+1)
+	if m.next != nil {
+		next += " (ready)"
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorPrimary).
+		Padding(1, 3).
+		Render(
+			ui.TitleStyle.Render("Up next: "+next) + "\n\n" +
+				fmt.Sprintf("Starting in %ds", m.countdown) + "\n\n" +
+				ui.HelpStyle.Render("enter play now  |  esc cancel"),
+		)
+	return lipgloss.NewStyle().Width(width).Padding(1, 0).Align(lipgloss.Center).Render(box)
+}
+
+// renderPrefetch describes the state of the next-episode prefetch.
+func (m PlayerModel) renderPrefetch() string {
+	style := lipgloss.NewStyle().Padding(0, 2).Foreground(ui.ColorSubtle)
+	switch {
+	case m.next != nil:
+		return style.Render(fmt.Sprintf("Next: episode %d ready (%s)", m.next.Episode, m.next.Release))
+	case m.prefetchErr != nil:
+		return style.Render(fmt.Sprintf("Next: episode %d not prefetched: %v", m.episode+1, m.prefetchErr))
+	case m.prefetching:
+		return style.Render(fmt.Sprintf("Next: prefetching episode %d...", m.episode+1))
+	}
+	return ""
+}
+
+// canPrefetch reports whether the next episode should still be prefetched:
+// there is a search to repeat, the next episode has aired and it isn't
+// already downloaded.
+func (m PlayerModel) canPrefetch() bool {
+	if m.prefetching || m.session == nil || m.torrentClient == nil || m.request.PrimaryTitle == "" || m.animeID == 0 {
+		return false
+	}
+	if !m.hasNextEpisode() {
+		return false
+	}
+	if nyaa.ParseRelease(m.release).Batch {
+		return false
+	}
+	if m.downloads != nil {
+		if _, ok := m.downloads.Lookup(m.animeID, m.episode+1); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// hasNextEpisode reports whether an episode after this one has aired. With
+// no known episode count it is assumed to have.
+func (m PlayerModel) hasNextEpisode() bool {
+	return m.lastEpisode <= 0 || m.episode < m.lastEpisode
+}
+
+// startPrefetch marks the prefetch as started and returns the command that
+// runs it.
+func (m *PlayerModel) startPrefetch() tea.Cmd {
+	m.prefetching = true
+	ctx, cancel := context.WithTimeout(context.Background(), streamTimeout)
+	m.prefetchStop = cancel
+
+	next := NavigateToPlayerMsg{
+		AnimeID:     m.animeID,
+		AnimeTitle:  m.animeTitle,
+		Episode:     m.episode + 1,
+		Request:     m.request,
+		LastEpisode: m.lastEpisode,
+	}
+	next.Request.Episode = next.Episode
+	next.Request.Query = "" // a hand-edited query names the current episode
+	return prefetchCmd(ctx, m.torrentClient, next, nyaa.ParseRelease(m.release), rankingProfile(m.cfg))
+}
+
+// Cleanup releases torrent and player resources. Playback closed before mpv
+// exited on its own keeps its position for resuming and is logged as a
+// watch session.
 func (m PlayerModel) Cleanup() {
+	if !m.done && m.history != nil && m.position.MediaID != 0 && m.position.Offset > 0 {
+		_ = m.history.Record(m.position) // best-effort
+	}
+	if !m.done && !m.started.IsZero() {
+		_ = m.sessions.Add(m.watchSession()) // best-effort
+	}
+	if m.prefetchStop != nil {
+		m.prefetchStop()
+	}
+	m.stopPlayback()
+}
+
+// stopPlayback closes mpv and the stream, leaving any prefetch running.
+func (m PlayerModel) stopPlayback() {
 	if m.streamCancel != nil {
 		m.streamCancel()
 	}
 	if m.session != nil {
 		m.session.Close()
 	}
-	if m.torrentClient != nil {
-		m.torrentClient.Close()
+	if m.streaming {
+		m.torrentClient.StopStream()
+	}
+}
+
+// countingDown reports whether the binge countdown overlay is showing.
+func (m PlayerModel) countingDown() bool {
+	return m.countdown > 0
+}
+
+// finishBinge ends the countdown, either starting the next episode or
+// returning as after normal playback.
+func (m PlayerModel) finishBinge(play bool) (PlayerModel, tea.Cmd) {
+	m.countdown = 0
+	if m.prefetchStop != nil {
+		m.prefetchStop()
 	}
+	return m, m.doneCmd(play)
 }
 
-func startStreamCmd(ctx context.Context, magnetURI string, cfg config.Config) tea.Cmd {
+// doneCmd reports the end of playback to the app.
+func (m PlayerModel) doneCmd(binge bool) tea.Cmd {
 	return func() tea.Msg {
-		tc, err := torrent.NewClient(cfg.DownloadDir)
-		if err != nil {
-			return playerReadyMsg{err: fmt.Errorf("create torrent client: %w", err)}
+		return PlayerDoneMsg{
+			AnimeID:     m.animeID,
+			AnimeTitle:  m.animeTitle,
+			Episode:     m.episode,
+			Request:     m.request,
+			Release:     m.release,
+			Next:        m.next,
+			Binge:       binge,
+			LastEpisode: m.lastEpisode,
+		}
+	}
+}
+
+func startStreamCmd(ctx context.Context, source string, cfg config.Config, tc *torrent.Client, start time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		if torrent.ClassifySource(source) == torrent.SourceLocalFile {
+			if _, err := os.Stat(source); err != nil {
+				return playerReadyMsg{err: fmt.Errorf("open local file: %w", err)}
+			}
+			session, err := player.StartFile(cfg.MpvPath, source, start)
+			if err != nil {
+				return playerReadyMsg{err: fmt.Errorf("start mpv: %w", err)}
+			}
+			return playerReadyMsg{session: session}
 		}
 
-		reader, filename, err := tc.AddMagnetAndStream(ctx, magnetURI)
+		reader, filename, err := tc.AddSourceAndStream(ctx, source)
 		if err != nil {
-			tc.Close()
 			return playerReadyMsg{err: fmt.Errorf("stream torrent: %w", err)}
 		}
 
-		session, err := player.Start(cfg.MpvPath, reader, filename)
+		session, err := player.Start(cfg.MpvPath, reader, filename, start)
 		if err != nil {
-			tc.Close()
+			tc.StopStream()
 			return playerReadyMsg{err: fmt.Errorf("start mpv: %w", err)}
 		}
 
-		return playerReadyMsg{torrentClient: tc, session: session}
+		return playerReadyMsg{streaming: true, session: session}
+	}
+}
+
+// downloadEntry describes the file being streamed for the downloads index.
+func (m PlayerModel) downloadEntry(complete bool) downloads.Entry {
+	e := downloads.Entry{
+		MediaID:  m.animeID,
+		Title:    m.animeTitle,
+		Episode:  m.episode,
+		Path:     m.torrentClient.ActiveFilePath(),
+		Complete: complete,
+	}
+	if t := m.torrentClient.ActiveTorrent(); t != nil {
+		e.InfoHash = t.InfoHash().HexString()
+	}
+	if f := m.torrentClient.ActiveFile(); f != nil {
+		e.Size = f.Length()
+	}
+	return e
+}
+
+func recordDownloadCmd(index *downloads.Index, e downloads.Entry) tea.Cmd {
+	if index == nil || e.Path == "" || e.MediaID == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		return downloadRecordedMsg{err: index.Put(e)}
+	}
+}
+
+// prefetchCmd finds the next episode's release and buffers its start.
+func prefetchCmd(ctx context.Context, tc *torrent.Client, next NavigateToPlayerMsg, like nyaa.Release, profile nyaa.Profile) tea.Cmd {
+	return func() tea.Msg {
+		next, err := findRelease(ctx, next, like, profile)
+		if err != nil {
+			return prefetchReadyMsg{err: err}
+		}
+		if err := tc.Prefetch(ctx, next.Source, prefetchBytes); err != nil {
+			return prefetchReadyMsg{err: err}
+		}
+		return prefetchReadyMsg{next: &next}
+	}
+}
+
+// findRelease searches for next.Request's episode and fills in the source
+// and release title of the best match. Releases the profile rejects are
+// skipped. When like has a group or resolution the match must share one
+// (see nyaa.PickRelease); otherwise the top-ranked release wins.
+func findRelease(ctx context.Context, next NavigateToPlayerMsg, like nyaa.Release, profile nyaa.Profile) (NavigateToPlayerMsg, error) {
+	if like.Resolution != "" {
+		next.Request.Quality = like.Resolution
+	}
+	items, err := nyaa.SearchWithFallback(ctx, next.Request)
+	if err != nil {
+		return next, err
 	}
+
+	var item nyaa.Item
+	var ok bool
+	if like.Group != "" || like.Resolution != "" {
+		item, ok = nyaa.PickRelease(profile.Rank(items), like, next.Request.Episodes()...)
+	} else {
+		item, ok = profile.Best(items, next.Request.Episodes()...)
+	}
+	if !ok {
+		return next, fmt.Errorf("no matching release")
+	}
+	next.Source = item.MagnetURI()
+	next.Release = item.Title
+	return next, nil
+}
+
+func playbackPosCmd(s *player.Session) tea.Cmd {
+	if s == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		offset, duration, err := s.Position()
+		return playbackPosMsg{offset: offset, duration: duration, err: err}
+	}
+}
+
+// recordPositionCmd saves where playback stopped so the episode can be
+// resumed. Nothing is saved if mpv never reported a position.
+func recordPositionCmd(store *history.Store, p history.Position) tea.Cmd {
+	if store == nil || p.MediaID == 0 || p.Offset <= 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		return positionRecordedMsg{err: store.Record(p)}
+	}
+}
+
+// watchSession describes this run of the player for the session log.
+func (m PlayerModel) watchSession() history.Session {
+	return history.Session{
+		MediaID:    m.animeID,
+		Episode:    m.episode,
+		Start:      m.started,
+		Watched:    m.watched,
+		Streamed:   m.streaming,
+		Downloaded: m.stats.Downloaded,
+	}
+}
+
+// recordSessionCmd logs a watch session. Runs where mpv never started are
+// not logged.
+func recordSessionCmd(log *history.SessionLog, s history.Session) tea.Cmd {
+	if log == nil || s.Start.IsZero() {
+		return nil
+	}
+	return func() tea.Msg {
+		return sessionRecordedMsg{err: log.Add(s)}
+	}
+}
+
+// formatOffset formats a playback offset as m:ss, or h:mm:ss past an hour.
+func formatOffset(d time.Duration) string {
+	s := int(d.Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
 }
 
 func waitForMpvCmd(s *player.Session) tea.Cmd {
 	return func() tea.Msg {
 		err := <-s.Wait()
-		return mpvExitMsg{err: err}
+		return mpvExitMsg{err: err, eof: s.EndReason() == "eof"}
 	}
 }
 
+func bingeTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return bingeTickMsg{}
+	})
+}
+
 func statsTickCmd() tea.Cmd {
 	return tea.Tick(time.Second, func(time.Time) tea.Msg {
 		return statsTickMsg{}
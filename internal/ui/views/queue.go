@@ -0,0 +1,238 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rayanxn/ani-tui/internal/torrent"
+	"github.com/rayanxn/ani-tui/internal/ui"
+)
+
+// queueTickMsg refreshes the queue view. gen ties the tick chain to the
+// model that started it so re-opening the view doesn't double the rate.
+type queueTickMsg struct{ gen int64 }
+
+type queueActionMsg struct {
+	err error
+}
+
+// QueueListItem wraps a torrent.QueueItem for bubbles/list rendering.
+type QueueListItem struct {
+	item torrent.QueueItem
+}
+
+func (i QueueListItem) Title() string {
+	if i.item.Batch {
+		return i.item.Title + " (batch)"
+	}
+	return fmt.Sprintf("%s - Episode %d", i.item.Title, i.item.Episode)
+}
+func (i QueueListItem) FilterValue() string { return i.item.Title }
+func (i QueueListItem) Description() string {
+	parts := []string{string(i.item.State)}
+	if i.item.BytesTotal > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f%% of %s", i.item.Progress()*100, torrent.FormatBytes(i.item.BytesTotal)))
+	}
+	if i.item.State == torrent.StateActive {
+		parts = append(parts, torrent.FormatSpeed(i.item.Speed))
+	}
+	if i.item.Priority != torrent.PriorityNormal {
+		parts = append(parts, "priority "+i.item.Priority.String())
+	}
+	if i.item.Err != "" {
+		parts = append(parts, i.item.Err)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// QueueModel shows the background download queue with per-item controls.
+type QueueModel struct {
+	manager       *torrent.Manager // nil if the queue failed to start
+	unavailable   error            // why manager is nil
+	list          list.Model
+	gen           int64
+	confirmCancel bool // true after the first "x" press on an item
+	err           error
+}
+
+// NewQueueModel creates a queue view for the given manager. A nil manager
+// shows unavailable instead of the queue.
+func NewQueueModel(manager *torrent.Manager, unavailable error) QueueModel {
+	base := list.NewDefaultDelegate()
+	base.Styles.SelectedTitle = base.Styles.SelectedTitle.
+		Foreground(ui.ColorPrimary).
+		BorderLeftForeground(ui.ColorPrimary)
+	base.Styles.SelectedDesc = base.Styles.SelectedDesc.
+		Foreground(ui.ColorSecondary).
+		BorderLeftForeground(ui.ColorPrimary)
+	l := list.New(nil, base, 0, 0)
+	l.Title = "Download Queue"
+	l.SetShowHelp(false)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.Styles.Title = ui.TitleStyle
+
+	m := QueueModel{manager: manager, unavailable: unavailable, list: l, gen: time.Now().UnixNano()}
+	m.reload()
+	return m
+}
+
+func (m QueueModel) Init() tea.Cmd {
+	return queueTickCmd(m.gen)
+}
+
+func (m QueueModel) Update(msg tea.Msg) (QueueModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height-6)
+		return m, nil
+
+	case queueTickMsg:
+		if msg.gen != m.gen {
+			return m, nil
+		}
+		m.reload()
+		return m, queueTickCmd(m.gen)
+
+	case queueActionMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		m.reload()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.err != nil {
+			if msg.String() == "esc" || msg.String() == "enter" {
+				m.err = nil
+				return m, nil
+			}
+		}
+
+		if msg.String() != "x" {
+			m.confirmCancel = false
+		}
+
+		item, ok := m.list.SelectedItem().(QueueListItem)
+		id := item.item.ID
+
+		switch msg.String() {
+		case "p", " ":
+			if ok {
+				return m, queueActionCmd(func() error { return m.manager.TogglePause(id) })
+			}
+			return m, nil
+		case "x":
+			if !ok {
+				return m, nil
+			}
+			if !m.confirmCancel {
+				m.confirmCancel = true
+				return m, nil
+			}
+			m.confirmCancel = false
+			return m, queueActionCmd(func() error { return m.manager.Cancel(id) })
+		case "K", "shift+up":
+			if ok {
+				m.list.CursorUp()
+				return m, queueActionCmd(func() error { return m.manager.Move(id, -1) })
+			}
+			return m, nil
+		case "J", "shift+down":
+			if ok {
+				m.list.CursorDown()
+				return m, queueActionCmd(func() error { return m.manager.Move(id, 1) })
+			}
+			return m, nil
+		case "+", "=":
+			if ok {
+				p := item.item.Priority + 1
+				return m, queueActionCmd(func() error { return m.manager.SetPriority(id, p) })
+			}
+			return m, nil
+		case "-":
+			if ok {
+				p := item.item.Priority - 1
+				return m, queueActionCmd(func() error { return m.manager.SetPriority(id, p) })
+			}
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// View renders the queue view within the given dimensions.
+func (m QueueModel) View(width, height int) string {
+	var footer string
+	if m.confirmCancel {
+		footer = ui.ErrorStyle.Render("Press x again to remove this download from the queue")
+	}
+
+	listHeight := height - lipgloss.Height(footer)
+	if listHeight < 0 {
+		listHeight = 0
+	}
+	m.list.SetSize(width, listHeight)
+
+	var body string
+	switch {
+	case m.err != nil:
+		body = lipgloss.NewStyle().Padding(1, 0).Render(ui.RenderError(m.err.Error()))
+	case m.manager == nil:
+		body = lipgloss.NewStyle().Padding(1, 0).Render(ui.RenderError(fmt.Sprintf("Download queue unavailable: %v", m.unavailable)))
+	case len(m.list.Items()) == 0:
+		body = lipgloss.NewStyle().Padding(1, 2).Render(
+			ui.TitleStyle.Render("Download Queue") + "\n" +
+				ui.HelpStyle.Render("Nothing queued. Press a on a torrent to download it in the background."))
+	default:
+		body = m.list.View()
+	}
+
+	content := body
+	if footer != "" {
+		content += "\n" + footer
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Render(content)
+}
+
+// reload refreshes the list from the manager, keeping the cursor on the same
+// item's position.
+func (m *QueueModel) reload() {
+	if m.manager == nil {
+		return
+	}
+	snapshot := m.manager.Items()
+	items := make([]list.Item, len(snapshot))
+	for i, it := range snapshot {
+		items[i] = QueueListItem{item: it}
+	}
+	cursor := m.list.Index()
+	m.list.SetItems(items)
+	if cursor < len(items) {
+		m.list.Select(cursor)
+	}
+}
+
+func queueActionCmd(action func() error) tea.Cmd {
+	return func() tea.Msg {
+		return queueActionMsg{err: action()}
+	}
+}
+
+func queueTickCmd(gen int64) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return queueTickMsg{gen: gen}
+	})
+}
@@ -15,9 +15,13 @@ import (
 )
 
 // SearchResultsMsg carries results from an AniList search.
+// Stale results come from the cache and are being refreshed.
 type SearchResultsMsg struct {
 	Results []anilist.Media
 	Err     error
+	Stale   bool
+	query   string
+	refresh bool // fetched to replace results already shown
 }
 
 // AnimeListItem wraps a Media for use in a bubbles/list.
@@ -50,7 +54,8 @@ type SearchModel struct {
 	input   textinput.Model
 	list    list.Model
 	spinner spinner.Model
-	focused bool // true when the text input has focus
+	focused bool   // true when the text input has focus
+	query   string // last submitted query
 	loading bool
 	err     error
 }
@@ -77,6 +82,8 @@ func NewSearchModel(client *anilist.Client) SearchModel {
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(false)
 	l.Styles.Title = ui.TitleStyle
+	// "d" opens downloads from this view, so it no longer pages.
+	l.KeyMap.NextPage.SetKeys("right", "l", "pgdown", "f")
 
 	s := spinner.New()
 	s.Spinner = spinner.Dot
@@ -104,7 +111,10 @@ func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
 	case SearchResultsMsg:
 		m.loading = false
 		if msg.Err != nil {
-			m.err = msg.Err
+			// A failed refresh keeps the cached results on screen.
+			if !msg.refresh || len(m.list.Items()) == 0 {
+				m.err = msg.Err
+			}
 			return m, nil
 		}
 		m.err = nil
@@ -113,6 +123,9 @@ func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
 			items[i] = AnimeListItem{media: media}
 		}
 		m.list.SetItems(items)
+		if msg.Stale {
+			return m, searchAniListCmd(m.client, msg.query, true)
+		}
 		return m, nil
 
 	case spinner.TickMsg:
@@ -133,9 +146,10 @@ func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
 					m.input.Blur()
 					m.loading = true
 					m.err = nil
+					m.query = query
 					return m, tea.Batch(
 						m.spinner.Tick,
-						searchAniListCmd(m.client, query),
+						searchAniListCmd(m.client, query, false),
 					)
 				}
 				return m, nil
@@ -161,6 +175,13 @@ func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
 
 		// List is focused
 		switch msg.String() {
+		case "ctrl+r":
+			if m.query == "" {
+				return m, nil
+			}
+			m.loading = true
+			m.err = nil
+			return m, tea.Batch(m.spinner.Tick, searchAniListCmd(m.client, m.query, true))
 		case "/":
 			m.focused = true
 			m.input.Focus()
@@ -230,10 +251,16 @@ func (m SearchModel) inputFocused() bool {
 	return m.focused
 }
 
-// searchAniListCmd returns a Cmd that searches AniList for anime.
-func searchAniListCmd(client *anilist.Client, query string) tea.Cmd {
+// searchAniListCmd returns a Cmd that searches AniList for anime. Cached
+// results are returned first unless refresh is set.
+func searchAniListCmd(client *anilist.Client, query string, refresh bool) tea.Cmd {
 	return func() tea.Msg {
+		if !refresh {
+			if results, fresh, ok := client.CachedSearchAnime(query, 1); ok {
+				return SearchResultsMsg{Results: results, Stale: !fresh, query: query}
+			}
+		}
 		results, err := client.SearchAnime(context.Background(), query, 1)
-		return SearchResultsMsg{Results: results, Err: err}
+		return SearchResultsMsg{Results: results, Err: err, query: query, refresh: refresh}
 	}
 }
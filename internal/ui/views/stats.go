@@ -0,0 +1,303 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rayanxn/ani-tui/internal/anilist"
+	"github.com/rayanxn/ani-tui/internal/downloads"
+	"github.com/rayanxn/ani-tui/internal/history"
+	"github.com/rayanxn/ani-tui/internal/torrent"
+	"github.com/rayanxn/ani-tui/internal/ui"
+)
+
+// statsWeeks is how many weeks the watch time sparkline covers.
+const statsWeeks = 26
+
+// statsFetchedMsg carries the user's AniList statistics. Stale statistics
+// come from the cache and are being refreshed.
+type statsFetchedMsg struct {
+	stats   anilist.UserStatistics
+	err     error
+	stale   bool
+	refresh bool // fetched to replace statistics already shown
+}
+
+// StatsModel shows the user's AniList statistics alongside watch sessions
+// and downloads tracked on this device.
+type StatsModel struct {
+	client     *anilist.Client
+	userID     int
+	sessionLog *history.SessionLog
+	downloads  *downloads.Index
+	stats      *anilist.UserStatistics // nil until loaded
+	sessions   []history.Session
+	viewport   viewport.Model
+	spinner    spinner.Model
+	width      int
+	loading    bool
+	err        error
+}
+
+// NewStatsModel creates a stats view. Without a token only local statistics
+// are shown.
+func NewStatsModel(client *anilist.Client, userID int, svc Services) StatsModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.SpinnerStyle
+
+	vp := viewport.New(0, 0)
+	vp.Style = lipgloss.NewStyle().Padding(0, 2)
+
+	return StatsModel{
+		client:     client,
+		userID:     userID,
+		sessionLog: svc.Sessions,
+		downloads:  svc.Downloads,
+		sessions:   svc.Sessions.Sessions(),
+		viewport:   vp,
+		spinner:    s,
+		loading:    client.Authenticated(),
+	}
+}
+
+func (m StatsModel) Init() tea.Cmd {
+	if !m.loading {
+		return nil
+	}
+	return tea.Batch(
+		m.spinner.Tick,
+		fetchStatsCmd(m.client, m.userID, false),
+	)
+}
+
+func (m StatsModel) Update(msg tea.Msg) (StatsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.viewport.Width = msg.Width
+		m.viewport.Height = max(0, msg.Height-2) // less the app's header and status bar
+		m.viewport.SetContent(m.render())
+		return m, nil
+
+	case statsFetchedMsg:
+		m.loading = false
+		if msg.err != nil {
+			// A failed refresh keeps the cached statistics on screen.
+			if !msg.refresh || m.stats == nil {
+				m.err = msg.err
+			}
+			return m, nil
+		}
+		m.err = nil
+		m.stats = &msg.stats
+		m.viewport.SetContent(m.render())
+		if msg.stale {
+			return m, fetchStatsCmd(m.client, m.userID, true)
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.err != nil {
+			if msg.String() == "esc" || msg.String() == "enter" {
+				m.err = nil
+				return m, nil
+			}
+		}
+
+		switch msg.String() {
+		case "ctrl+r":
+			m.sessions = m.sessionLog.Sessions()
+			m.viewport.SetContent(m.render())
+			if !m.client.Authenticated() {
+				return m, nil
+			}
+			m.loading = true
+			m.err = nil
+			return m, tea.Batch(m.spinner.Tick, fetchStatsCmd(m.client, m.userID, true))
+		case "g":
+			m.viewport.GotoTop()
+			return m, nil
+		case "G":
+			m.viewport.GotoBottom()
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// View renders the stats view within the given dimensions.
+func (m StatsModel) View(width, height int) string {
+	var body string
+	switch {
+	case m.loading && m.stats == nil:
+		body = lipgloss.NewStyle().Padding(1, 2).Render(m.spinner.View() + " Loading stats...")
+	case m.err != nil:
+		body = lipgloss.NewStyle().Padding(1, 0).Render(ui.RenderError(m.err.Error()))
+	default:
+		body = m.viewport.View()
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Render(body)
+}
+
+// render lays out every section at the current width.
+func (m StatsModel) render() string {
+	width := max(20, m.width-4)
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.ColorPrimary)
+	subtleStyle := lipgloss.NewStyle().Foreground(ui.ColorSubtle)
+
+	var sections []string
+	switch {
+	case m.stats != nil:
+		sections = append(sections, m.renderAniList(width)...)
+	case !m.client.Authenticated():
+		sections = append(sections, subtleStyle.Render("Log in to AniList to see statistics for your list."))
+	}
+
+	// Statistics tracked on this device.
+	sessions := m.sessions
+	watched, streamed := history.Totals(sessions)
+	local := []string{
+		ui.TitleStyle.Render("On This Device"),
+		fmt.Sprintf("%s watched in %d sessions · %s streamed",
+			formatHours(watched), len(sessions), torrent.FormatBytes(streamed)),
+	}
+	var onDisk int64
+	var files int
+	for _, e := range m.downloads.Entries() {
+		if e.Complete {
+			onDisk += e.Size
+			files++
+		}
+	}
+	local = append(local, fmt.Sprintf("%d downloaded episodes · %s on disk", files, torrent.FormatBytes(onDisk)))
+
+	weeks := history.Weekly(sessions, time.Now(), statsWeeks)
+	minutes := make([]int, len(weeks))
+	for i, w := range weeks {
+		minutes[i] = int(w.Minutes())
+	}
+	local = append(local, "",
+		labelStyle.Render(fmt.Sprintf("Hours per Week (last %d)", statsWeeks)),
+		ui.RenderSparkline(minutes)+subtleStyle.Render("  this week "+formatHours(weeks[len(weeks)-1])))
+	sections = append(sections, strings.Join(local, "\n"))
+
+	return strings.Join(sections, "\n\n")
+}
+
+// renderAniList renders the summary and breakdowns from AniList.
+func (m StatsModel) renderAniList(width int) []string {
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(ui.ColorPrimary)
+	valueStyle := lipgloss.NewStyle().Foreground(ui.ColorText)
+	subtleStyle := lipgloss.NewStyle().Foreground(ui.ColorSubtle)
+	s := m.stats
+
+	summary := []string{
+		labelStyle.Render("Anime: ") + valueStyle.Render(fmt.Sprint(s.Count)),
+		labelStyle.Render("Episodes: ") + valueStyle.Render(fmt.Sprint(s.EpisodesWatched)),
+		labelStyle.Render("Days watched: ") + valueStyle.Render(fmt.Sprintf("%.1f", float64(s.MinutesWatched)/(60*24))),
+		labelStyle.Render("Mean score: ") + valueStyle.Render(fmt.Sprintf("%.1f", s.MeanScore)),
+	}
+	sections := []string{ui.TitleStyle.Render("AniList Stats") + "\n" + strings.Join(summary, subtleStyle.Render("  ·  "))}
+
+	chart := func(title string, rows []anilist.UserStatistic, label func(anilist.UserStatistic) string) {
+		if len(rows) == 0 {
+			return
+		}
+		bars := make([]ui.Bar, len(rows))
+		for i, r := range rows {
+			bars[i] = ui.Bar{Label: label(r), Value: r.Count}
+		}
+		sections = append(sections, labelStyle.Render(title)+"\n"+ui.RenderBarChart(bars, min(width, 80)))
+	}
+	chart("Genres", s.Genres, func(r anilist.UserStatistic) string { return r.Genre })
+	chart("Formats", s.Formats, func(r anilist.UserStatistic) string { return formatMediaFormat(r.Format) })
+	chart("Studios", s.Studios, func(r anilist.UserStatistic) string {
+		if r.Studio == nil {
+			return "Unknown"
+		}
+		return truncate(r.Studio.Name, 24)
+	})
+	scores := slices.Clone(s.Scores)
+	slices.SortFunc(scores, func(a, b anilist.UserStatistic) int { return a.Score - b.Score })
+	chart("Scores", scores, func(r anilist.UserStatistic) string { return fmt.Sprint(r.Score) })
+
+	if years := s.ReleaseYears; len(years) > 0 {
+		// One block per year, including years with nothing watched.
+		first, last, peak := years[0], years[0], years[0]
+		for _, y := range years {
+			if y.ReleaseYear < first.ReleaseYear {
+				first = y
+			}
+			if y.ReleaseYear > last.ReleaseYear {
+				last = y
+			}
+			if y.Count > peak.Count {
+				peak = y
+			}
+		}
+		counts := make([]int, last.ReleaseYear-first.ReleaseYear+1)
+		for _, y := range years {
+			counts[y.ReleaseYear-first.ReleaseYear] = y.Count
+		}
+		sections = append(sections, labelStyle.Render("Release Years")+"\n"+
+			subtleStyle.Render(fmt.Sprint(first.ReleaseYear)+" ")+ui.RenderSparkline(counts)+
+			subtleStyle.Render(fmt.Sprintf(" %d  · most from %d (%d)", last.ReleaseYear, peak.ReleaseYear, peak.Count)))
+	}
+	return sections
+}
+
+// formatMediaFormat names a media format, keeping acronyms like TV and OVA.
+func formatMediaFormat(f string) string {
+	switch f {
+	case "TV", "OVA", "ONA":
+		return f
+	case "TV_SHORT":
+		return "TV Short"
+	}
+	return formatSource(f)
+}
+
+// formatHours formats a watch time in hours, with one decimal below ten.
+func formatHours(d time.Duration) string {
+	h := d.Hours()
+	if h < 10 {
+		return fmt.Sprintf("%.1fh", h)
+	}
+	return fmt.Sprintf("%.0fh", h)
+}
+
+// fetchStatsCmd fetches the user's statistics. Cached statistics are
+// returned first unless refresh is set.
+func fetchStatsCmd(client *anilist.Client, userID int, refresh bool) tea.Cmd {
+	return func() tea.Msg {
+		if !refresh {
+			if stats, fresh, ok := client.CachedUserStats(userID); ok {
+				return statsFetchedMsg{stats: stats, stale: !fresh}
+			}
+		}
+		stats, err := client.GetUserStats(context.Background(), userID)
+		return statsFetchedMsg{stats: stats, err: err, refresh: refresh}
+	}
+}
@@ -2,22 +2,120 @@ package views
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/rayanxn/ani-tui/internal/config"
 	"github.com/rayanxn/ani-tui/internal/nyaa"
+	"github.com/rayanxn/ani-tui/internal/torrent"
 	"github.com/rayanxn/ani-tui/internal/ui"
 )
 
 // NyaaResultsMsg carries nyaa search results back to the torrents view.
+// Partial results arrive while alt-title queries are still running.
 type NyaaResultsMsg struct {
 	Results []nyaa.Item
 	Err     error
+	Partial bool
+	paging  nyaa.Paging           // how far the primary query was read; set on the final update
+	seq     int                   // search that produced the results; stale ones are ignored
+	next    <-chan NyaaResultsMsg // further updates of a partial search
+}
+
+// nyaaPageMsg carries a further page of results for the current search.
+type nyaaPageMsg struct {
+	page  int
+	items []nyaa.Item
+	more  bool
+	err   error
+	seq   int
+}
+
+type torrentDetailsMsg struct {
+	key     string
+	details nyaa.Details
+	err     error
+}
+
+// torrentDetails is the fetch state of one torrent's view page.
+type torrentDetails struct {
+	details nyaa.Details
+	loading bool
+	err     error
+}
+
+// errQueueUnavailable is reported when adding to a download queue that failed
+// to start.
+var errQueueUnavailable = errors.New("download queue is unavailable")
+
+type queuedMsg struct {
+	title string
+	err   error
+}
+
+// resolutionFilters are the choices the resolution toggle cycles through;
+// "" shows every resolution.
+var resolutionFilters = []string{"", "480p", "720p", "1080p", "2160p"}
+
+// torrentFilter holds the quick toggles applied to search results.
+type torrentFilter struct {
+	trustedOnly bool
+	hideRemakes bool
+	hideBatches bool
+	resolution  string
+	text        string
+}
+
+// match reports whether it passes every active filter.
+func (f torrentFilter) match(it nyaa.Item) bool {
+	if f.trustedOnly && !it.IsTrusted() {
+		return false
+	}
+	if f.hideRemakes && it.IsRemake() {
+		return false
+	}
+	if f.hideBatches || f.resolution != "" {
+		rel := nyaa.ParseRelease(it.Title)
+		if f.hideBatches && rel.Batch {
+			return false
+		}
+		if f.resolution != "" && rel.Resolution != f.resolution {
+			return false
+		}
+	}
+	if f.text != "" && !strings.Contains(strings.ToLower(it.Title), strings.ToLower(f.text)) {
+		return false
+	}
+	return true
+}
+
+// labels describes the active filters for the header.
+func (f torrentFilter) labels() []string {
+	var out []string
+	if f.trustedOnly {
+		out = append(out, "trusted")
+	}
+	if f.hideRemakes {
+		out = append(out, "no remakes")
+	}
+	if f.hideBatches {
+		out = append(out, "no batches")
+	}
+	if f.resolution != "" {
+		out = append(out, f.resolution)
+	}
+	if f.text != "" {
+		out = append(out, fmt.Sprintf("%q", f.text))
+	}
+	return out
 }
 
 // TorrentListItem wraps a nyaa.Item for bubbles/list rendering.
@@ -50,16 +148,40 @@ func (d torrentDelegate) Render(w io.Writer, m list.Model, index int, item list.
 
 // TorrentsModel displays nyaa search results for a selected episode.
 type TorrentsModel struct {
-	animeID int
-	request nyaa.SearchRequest
-	list    list.Model
-	spinner spinner.Model
-	loading bool
-	err     error
+	animeID     int
+	request     nyaa.SearchRequest
+	lastEpisode int // last aired episode, passed on to the player
+	queue       *torrent.Manager
+	profile     nyaa.Profile
+	results     []nyaa.Item // ranked results before sorting and filtering
+	sortKey     nyaa.SortKey
+	filter      torrentFilter
+	list        list.Model
+	spinner     spinner.Model
+	input       textinput.Model
+	inputOpen   bool // true while the "open source" prompt has focus
+	search      textinput.Model
+	searching   bool // true while the free-text filter has focus
+	query       textinput.Model
+	editing     bool // true while the query editor has focus
+	searchSeq   int
+	searchCtx   context.Context           // context of the running search
+	cancel      context.CancelFunc        // stops the running search
+	streaming   bool                      // true while partial results are still growing
+	page        int                       // last results page loaded
+	hasMore     bool                      // nyaa.si may have further pages
+	paging      bool                      // true while a further page is loading
+	details     map[string]torrentDetails // keyed by view page URL
+	showInfo    bool                      // true while the detail pane replaces the list
+	infoTop     int                       // first visible line of the detail pane
+	notice      string
+	loading     bool
+	err         error
 }
 
-// NewTorrentsModel creates a torrents results view.
-func NewTorrentsModel(animeID int, req nyaa.SearchRequest) TorrentsModel {
+// NewTorrentsModel creates a torrents results view ranked by profile. Items
+// can be sent to the given download queue.
+func NewTorrentsModel(animeID int, req nyaa.SearchRequest, lastEpisode int, queue *torrent.Manager, profile nyaa.Profile) TorrentsModel {
 	base := list.NewDefaultDelegate()
 	base.Styles.SelectedTitle = base.Styles.SelectedTitle.
 		Foreground(ui.ColorPrimary).
@@ -80,19 +202,44 @@ func NewTorrentsModel(animeID int, req nyaa.SearchRequest) TorrentsModel {
 	s.Spinner = spinner.Dot
 	s.Style = ui.SpinnerStyle
 
+	ti := textinput.New()
+	ti.Placeholder = "Magnet, .torrent URL/path or video file..."
+	ti.CharLimit = 2048
+	ti.Width = 60
+
+	qi := textinput.New()
+	qi.Placeholder = "nyaa.si query..."
+	qi.CharLimit = 256
+	qi.Width = 60
+
+	fi := textinput.New()
+	fi.Placeholder = "Filter titles..."
+	fi.CharLimit = 256
+	fi.Width = 40
+
+	ctx, cancel := context.WithCancel(context.Background())
 	return TorrentsModel{
-		animeID: animeID,
-		request: req,
-		list:    l,
-		spinner: s,
-		loading: true,
+		searchCtx:   ctx,
+		cancel:      cancel,
+		animeID:     animeID,
+		request:     req,
+		lastEpisode: lastEpisode,
+		queue:       queue,
+		profile:     profile,
+		list:        l,
+		spinner:     s,
+		input:       ti,
+		search:      fi,
+		query:       qi,
+		details:     make(map[string]torrentDetails),
+		loading:     true,
 	}
 }
 
 func (m TorrentsModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
-		searchNyaaCmd(m.request),
+		searchNyaaCmd(m.searchCtx, m.request, m.searchSeq),
 	)
 }
 
@@ -103,21 +250,68 @@ func (m TorrentsModel) Update(msg tea.Msg) (TorrentsModel, tea.Cmd) {
 		return m, nil
 
 	case NyaaResultsMsg:
-		m.loading = false
+		if msg.seq != m.searchSeq {
+			return m, nil
+		}
+		m.streaming = msg.Partial
 		if msg.Err != nil {
+			m.loading = false
 			m.err = msg.Err
 			return m, nil
 		}
 		m.err = nil
-		items := make([]list.Item, len(msg.Results))
-		for i, r := range msg.Results {
-			items[i] = TorrentListItem{item: r}
+		cursor := m.list.Index()
+		m.results = m.profile.Rank(msg.Results)
+		if !msg.Partial {
+			// Judged on the raw pages: filtering leaves far fewer than a
+			// page even when nyaa.si has more.
+			m.page = msg.paging.Page
+			m.hasMore = msg.paging.More
+		}
+		m.refreshList()
+		if !m.loading {
+			m.list.Select(cursor)
+		}
+		m.loading = false
+		if msg.Partial {
+			return m, waitNyaaCmd(msg.next)
+		}
+		return m, nil
+
+	case nyaaPageMsg:
+		if msg.seq != m.searchSeq {
+			return m, nil
+		}
+		m.paging = false
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("Page %d failed: %v", msg.page, msg.err)
+			return m, nil
+		}
+		m.page = msg.page
+		m.hasMore = msg.more
+		added := m.appendResults(msg.items)
+		m.notice = fmt.Sprintf("Page %d: %d new torrents", msg.page, added)
+		return m, nil
+
+	case torrentDetailsMsg:
+		if errors.Is(msg.err, context.Canceled) {
+			// The search moved on; fetch again if info is reopened.
+			delete(m.details, msg.key)
+			return m, nil
+		}
+		m.details[msg.key] = torrentDetails{details: msg.details, err: msg.err}
+		return m, nil
+
+	case queuedMsg:
+		if msg.err != nil {
+			m.notice = "Not queued: " + msg.err.Error()
+		} else {
+			m.notice = "Queued for download: " + msg.title
 		}
-		m.list.SetItems(items)
 		return m, nil
 
 	case spinner.TickMsg:
-		if m.loading {
+		if m.loading || m.streaming {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
@@ -125,6 +319,75 @@ func (m TorrentsModel) Update(msg tea.Msg) (TorrentsModel, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.inputOpen {
+			switch msg.String() {
+			case "esc":
+				m.inputOpen = false
+				m.input.Blur()
+				m.input.Reset()
+				return m, nil
+			case "enter":
+				source := torrent.NormalizeSource(m.input.Value())
+				if source == "" {
+					return m, nil
+				}
+				m.inputOpen = false
+				m.input.Blur()
+				m.input.Reset()
+				return m, m.playCmd(source, "")
+			}
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+
+		if m.editing {
+			switch msg.String() {
+			case "esc":
+				m.editing = false
+				m.query.Blur()
+				return m, nil
+			case "enter":
+				m.editing = false
+				m.query.Blur()
+				q := strings.TrimSpace(m.query.Value())
+				if q == "" {
+					return m, nil
+				}
+				// Searching the generated text again restores the default
+				// filtered search.
+				m.request.Query = ""
+				if q != m.request.QueryText() {
+					m.request.Query = q
+				}
+				return m.rerun()
+			}
+			var cmd tea.Cmd
+			m.query, cmd = m.query.Update(msg)
+			return m, cmd
+		}
+
+		if m.searching {
+			switch msg.String() {
+			case "esc":
+				m.searching = false
+				m.search.Blur()
+				m.search.Reset()
+				m.filter.text = ""
+				m.refreshList()
+				return m, nil
+			case "enter":
+				m.searching = false
+				m.search.Blur()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.search, cmd = m.search.Update(msg)
+			m.filter.text = strings.TrimSpace(m.search.Value())
+			m.refreshList()
+			return m, cmd
+		}
+
 		if m.err != nil {
 			if msg.String() == "esc" || msg.String() == "enter" {
 				m.err = nil
@@ -132,8 +395,91 @@ func (m TorrentsModel) Update(msg tea.Msg) (TorrentsModel, tea.Cmd) {
 			}
 		}
 
+		if m.showInfo {
+			switch msg.String() {
+			case "esc", "i":
+				m.showInfo = false
+				return m, nil
+			case "j", "down":
+				m.infoTop++
+				return m, nil
+			case "k", "up":
+				m.infoTop = max(0, m.infoTop-1)
+				return m, nil
+			case "enter", "a":
+				// Fall through to play or queue the torrent being viewed.
+			default:
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
-		case "enter":
+		case "ctrl+r":
+			// Bypass the cache for this search only.
+			m.request.Options.Refresh = true
+			m, cmd := m.rerun()
+			m.request.Options.Refresh = false
+			return m, cmd
+		case "n":
+			if !m.hasMore || m.paging {
+				return m, nil
+			}
+			m.paging = true
+			m.notice = fmt.Sprintf("Loading page %d...", m.page+1)
+			return m, searchPageCmd(m.searchCtx, m.request, m.page+1, m.searchSeq)
+		case "e":
+			m.editing = true
+			m.query.SetValue(m.request.QueryText())
+			m.query.CursorEnd()
+			m.query.Focus()
+			return m, textinput.Blink
+		case "c":
+			m.request.Options.Category = m.request.Options.Category.Next()
+			return m.rerun()
+		case "F":
+			m.request.Options.Filter = m.request.Options.Filter.Next()
+			return m.rerun()
+		case "S":
+			m.request.Options.Sort = m.request.Options.Sort.Next()
+			return m.rerun()
+		case "i":
+			item, ok := m.list.SelectedItem().(TorrentListItem)
+			if !ok {
+				return m, nil
+			}
+			m.showInfo = true
+			m.infoTop = 0
+			key := nyaa.ViewURL(item.item)
+			if _, cached := m.details[key]; cached || key == "" {
+				return m, nil
+			}
+			m.details[key] = torrentDetails{loading: true}
+			return m, fetchDetailsCmd(m.searchCtx, key, item.item)
+		case "s":
+			m.sortKey = m.sortKey.Next()
+			m.refreshList()
+			return m, nil
+		case "t":
+			m.filter.trustedOnly = !m.filter.trustedOnly
+			m.refreshList()
+			return m, nil
+		case "m":
+			m.filter.hideRemakes = !m.filter.hideRemakes
+			m.refreshList()
+			return m, nil
+		case "B":
+			m.filter.hideBatches = !m.filter.hideBatches
+			m.refreshList()
+			return m, nil
+		case "r":
+			m.filter.resolution = nyaa.Cycle(resolutionFilters, m.filter.resolution)
+			m.refreshList()
+			return m, nil
+		case "/":
+			m.searching = true
+			m.search.Focus()
+			return m, textinput.Blink
+		case "a":
 			item, ok := m.list.SelectedItem().(TorrentListItem)
 			if !ok {
 				return m, nil
@@ -143,14 +489,33 @@ func (m TorrentsModel) Update(msg tea.Msg) (TorrentsModel, tea.Cmd) {
 				m.err = fmt.Errorf("selected item is missing an info hash")
 				return m, nil
 			}
-			return m, func() tea.Msg {
-				return NavigateToPlayerMsg{
-					MagnetURI:  magnetURI,
-					AnimeID:    m.animeID,
-					AnimeTitle: m.request.PrimaryTitle,
-					Episode:    m.request.Episode,
-				}
+			rel := nyaa.ParseRelease(item.item.Title)
+			qi := torrent.QueueItem{
+				Source:  magnetURI,
+				Title:   m.request.PrimaryTitle,
+				MediaID: m.animeID,
+				Episode: m.request.Episode,
+				Batch:   rel.Batch,
+			}
+			if qi.Batch {
+				qi.Episode = 0
+			}
+			return m, enqueueCmd(m.queue, qi, item.item.Title)
+		case "o":
+			m.inputOpen = true
+			m.input.Focus()
+			return m, textinput.Blink
+		case "enter":
+			item, ok := m.list.SelectedItem().(TorrentListItem)
+			if !ok {
+				return m, nil
+			}
+			magnetURI := item.item.MagnetURI()
+			if magnetURI == "" {
+				m.err = fmt.Errorf("selected item is missing an info hash")
+				return m, nil
 			}
+			return m, m.playCmd(magnetURI, item.item.Title)
 		}
 
 		var cmd tea.Cmd
@@ -165,11 +530,37 @@ func (m TorrentsModel) Update(msg tea.Msg) (TorrentsModel, tea.Cmd) {
 
 // View renders the torrents results view.
 func (m TorrentsModel) View(width, height int) string {
-	queryLabel := nyaa.BuildSearchQuery(m.request.PrimaryTitle, m.request.Episode, m.request.Quality)
+	opts := m.request.Options
+	queryLabel := fmt.Sprintf("%s  (%s · %s · nyaa sort: %s)",
+		m.request.QueryText(), opts.Category.Label(), opts.Filter.Label(), opts.Sort.Label())
+	controls := append([]string{"sort: " + m.sortKey.Label()}, m.filter.labels()...)
+	if m.page > 1 {
+		controls = append(controls, fmt.Sprintf("pages: %d", m.page))
+	}
 	header := lipgloss.NewStyle().Padding(1, 2).Render(
 		ui.TitleStyle.Render("Episode Search") + "\n" +
-			lipgloss.NewStyle().Foreground(ui.ColorSubtle).Render(queryLabel),
+			lipgloss.NewStyle().Foreground(ui.ColorSubtle).Render(queryLabel) + "  " +
+			lipgloss.NewStyle().Foreground(ui.ColorSecondary).Render("["+strings.Join(controls, " · ")+"]"),
 	)
+	if m.streaming {
+		header += "\n" + lipgloss.NewStyle().Padding(0, 2).Foreground(ui.ColorSubtle).Render(
+			m.spinner.View()+" Searching alt titles...")
+	}
+	if m.notice != "" {
+		header += "\n" + lipgloss.NewStyle().Padding(0, 2).Foreground(ui.ColorSuccess).Render(m.notice)
+	}
+	if m.inputOpen {
+		header += "\n" + lipgloss.NewStyle().Padding(0, 2).Render(
+			"Open: "+m.input.View()) + "\n"
+	}
+	if m.searching {
+		header += "\n" + lipgloss.NewStyle().Padding(0, 2).Render(
+			"Filter: "+m.search.View()) + "\n"
+	}
+	if m.editing {
+		header += "\n" + lipgloss.NewStyle().Padding(0, 2).Render(
+			"Query: "+m.query.View()) + "\n"
+	}
 
 	listHeight := height - lipgloss.Height(header)
 	if listHeight < 0 {
@@ -179,23 +570,268 @@ func (m TorrentsModel) View(width, height int) string {
 
 	var body string
 	switch {
+	case m.showInfo:
+		body = m.renderInfo(width, listHeight)
 	case m.loading:
 		body = lipgloss.NewStyle().Padding(1, 2).Render(m.spinner.View() + " Searching nyaa.si...")
 	case m.err != nil:
 		body = lipgloss.NewStyle().Padding(1, 0).Render(ui.RenderError(m.err.Error()))
+	case len(m.list.Items()) == 0 && len(m.results) > 0:
+		body = ui.HelpStyle.Render(fmt.Sprintf("  All %d torrents hidden by filters", len(m.results)))
 	case len(m.list.Items()) == 0:
 		body = ui.HelpStyle.Render("  No torrents found for this episode")
 	default:
 		body = m.list.View()
+		if m.hasMore {
+			body += "\n" + ui.HelpStyle.Render("  n: load more results")
+		}
 	}
 
 	content := header + "\n" + body
 	return lipgloss.NewStyle().Width(width).Height(height).Render(content)
 }
 
-func searchNyaaCmd(req nyaa.SearchRequest) tea.Cmd {
+// renderInfo draws the detail pane for the selected torrent, scrolled to
+// infoTop and clipped to height.
+func (m TorrentsModel) renderInfo(width, height int) string {
+	item, ok := m.list.SelectedItem().(TorrentListItem)
+	if !ok {
+		return ""
+	}
+	it := item.item
+	label := lipgloss.NewStyle().Foreground(ui.ColorSubtle)
+	wrap := lipgloss.NewStyle().Width(max(20, width-4))
+
+	lines := []string{wrap.Render(ui.TitleStyle.Render(it.Title)), ""}
+
+	rel := nyaa.ParseRelease(it.Title)
+	meta := []string{}
+	if rel.Group != "" {
+		meta = append(meta, "Group: "+rel.Group)
+	}
+	switch {
+	case rel.Batch && rel.EpisodeEnd > 0:
+		meta = append(meta, fmt.Sprintf("Episodes: %d-%d", rel.Episode, rel.EpisodeEnd))
+	case rel.Batch:
+		meta = append(meta, "Batch")
+	case rel.Episode > 0:
+		meta = append(meta, fmt.Sprintf("Episode: %d", rel.Episode))
+	}
+	if rel.Season > 0 {
+		meta = append(meta, fmt.Sprintf("Season: %d", rel.Season))
+	}
+	if rel.Resolution != "" {
+		meta = append(meta, "Resolution: "+rel.Resolution)
+	}
+	if rel.Codec != "" {
+		meta = append(meta, "Codec: "+rel.Codec)
+	}
+	lines = append(lines,
+		"Title: "+rel.Title,
+		strings.Join(meta, "  ·  "),
+		it.Summary()+"  |  "+it.PubDate,
+		"",
+	)
+
+	d, fetched := m.details[nyaa.ViewURL(it)]
+	switch {
+	case !fetched:
+		lines = append(lines, label.Render("No view page for this torrent."))
+	case d.loading:
+		lines = append(lines, label.Render("Loading torrent page..."))
+	case d.err != nil:
+		lines = append(lines, ui.ErrorStyle.Render("Could not load torrent page: "+d.err.Error()))
+	default:
+		lines = append(lines,
+			fmt.Sprintf("Submitter: %s  |  Comments: %d", d.details.Submitter, d.details.Comments),
+		)
+		if d.details.Information != "" {
+			lines = append(lines, "Information: "+d.details.Information)
+		}
+		lines = append(lines, "", ui.TitleStyle.Render(fmt.Sprintf("Files (%d)", len(d.details.Files))))
+		for _, f := range d.details.Files {
+			lines = append(lines, wrap.Render("  "+f.Path+" "+label.Render(f.Size)))
+		}
+		if d.details.Description != "" {
+			lines = append(lines, "", ui.TitleStyle.Render("Description"), wrap.Render(d.details.Description))
+		}
+	}
+
+	all := strings.Split(strings.Join(lines, "\n"), "\n")
+	top := min(m.infoTop, max(0, len(all)-height))
+	end := min(len(all), top+height)
+	return lipgloss.NewStyle().Padding(0, 2).Render(strings.Join(all[top:end], "\n"))
+}
+
+// playCmd navigates to the player for the given source, keeping the current
+// anime and episode so progress sync still applies. release is the nyaa
+// title of the chosen torrent, if known.
+func (m TorrentsModel) playCmd(source, release string) tea.Cmd {
 	return func() tea.Msg {
-		results, err := nyaa.SearchWithFallback(context.Background(), req)
-		return NyaaResultsMsg{Results: results, Err: err}
+		return NavigateToPlayerMsg{
+			Source:      source,
+			AnimeID:     m.animeID,
+			AnimeTitle:  m.request.PrimaryTitle,
+			Episode:     m.request.Episode,
+			Request:     m.request,
+			Release:     release,
+			LastEpisode: m.lastEpisode,
+		}
+	}
+}
+
+// inputFocused reports whether a text prompt currently has focus.
+func (m TorrentsModel) inputFocused() bool {
+	return m.inputOpen || m.searching || m.editing
+}
+
+// rerun repeats the search after the query or options changed.
+func (m TorrentsModel) rerun() (TorrentsModel, tea.Cmd) {
+	m.Cleanup()
+	m.searchCtx, m.cancel = context.WithCancel(context.Background())
+	m.searchSeq++
+	m.loading = true
+	m.streaming = false
+	m.err = nil
+	m.results = nil
+	m.page = 0
+	m.hasMore = false
+	m.paging = false
+	m.showInfo = false
+	m.list.SetItems(nil)
+	return m, tea.Batch(m.spinner.Tick, searchNyaaCmd(m.searchCtx, m.request, m.searchSeq))
+}
+
+// Cleanup stops the running search.
+func (m TorrentsModel) Cleanup() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+}
+
+// infoOpen reports whether the detail pane is showing.
+func (m TorrentsModel) infoOpen() bool {
+	return m.showInfo
+}
+
+// refreshList rebuilds the list from the results using the current sort
+// order and filters.
+func (m *TorrentsModel) refreshList() {
+	var items []list.Item
+	for _, r := range nyaa.SortItems(m.results, m.sortKey) {
+		if m.filter.match(r) {
+			items = append(items, TorrentListItem{item: r})
+		}
+	}
+	m.list.SetItems(items)
+	m.list.ResetSelected()
+}
+
+// appendResults merges further results into the ranked set, skipping ones
+// already shown, and keeps the cursor where it was. It returns how many
+// torrents were new.
+func (m *TorrentsModel) appendResults(items []nyaa.Item) int {
+	seen := make(map[string]bool, len(m.results))
+	for _, r := range m.results {
+		seen[resultKey(r)] = true
+	}
+	added := 0
+	merged := m.results
+	for _, it := range items {
+		if k := resultKey(it); !seen[k] {
+			seen[k] = true
+			merged = append(merged, it)
+			added++
+		}
+	}
+	if added == 0 {
+		return 0
+	}
+	cursor := m.list.Index()
+	m.results = m.profile.Rank(merged)
+	m.refreshList()
+	m.list.Select(cursor)
+	return added
+}
+
+// resultKey identifies a result across pages.
+func resultKey(it nyaa.Item) string {
+	if it.InfoHash != "" {
+		return it.InfoHash
+	}
+	return it.Link
+}
+
+// rankingProfile converts the configured ranking into a nyaa scoring profile.
+func rankingProfile(cfg config.Config) nyaa.Profile {
+	r := cfg.Ranking
+	return nyaa.Profile{
+		PreferredGroups: r.PreferredGroups,
+		BlockedGroups:   r.BlockedGroups,
+		Resolutions:     r.Resolutions,
+		Codecs:          r.Codecs,
+		MinSeeders:      r.MinSeeders,
+		TrustedOnly:     r.TrustedOnly,
+		SkipRemakes:     !r.AllowRemakes,
+	}
+}
+
+func fetchDetailsCmd(ctx context.Context, key string, it nyaa.Item) tea.Cmd {
+	return func() tea.Msg {
+		d, err := nyaa.FetchDetails(ctx, it)
+		return torrentDetailsMsg{key: key, details: d, err: err}
+	}
+}
+
+func enqueueCmd(queue *torrent.Manager, item torrent.QueueItem, title string) tea.Cmd {
+	return func() tea.Msg {
+		if queue == nil {
+			return queuedMsg{title: title, err: errQueueUnavailable}
+		}
+		return queuedMsg{title: title, err: queue.Enqueue(item)}
+	}
+}
+
+func searchPageCmd(ctx context.Context, req nyaa.SearchRequest, page, seq int) tea.Cmd {
+	return func() tea.Msg {
+		items, more, err := nyaa.SearchPage(ctx, req, page)
+		return nyaaPageMsg{page: page, items: items, more: more, err: err, seq: seq}
+	}
+}
+
+// searchNyaaCmd starts the search and returns its first update. Each query
+// that adds results sends a partial update; the final one closes the stream.
+func searchNyaaCmd(ctx context.Context, req nyaa.SearchRequest, seq int) tea.Cmd {
+	return func() tea.Msg {
+		// Buffered for every possible update: the primary query, its second
+		// page, one per alternative query and the final result. A cancelled
+		// search stops sending, so a view that stopped listening never
+		// strands the goroutine.
+		ch := make(chan NyaaResultsMsg, len(req.AltTitles)+len(req.BaseTitles)+3)
+		send := func(msg NyaaResultsMsg) {
+			select {
+			case ch <- msg:
+			case <-ctx.Done():
+			}
+		}
+		go func() {
+			defer close(ch)
+			results, paging, err := nyaa.SearchWithFallbackFunc(ctx, req, func(items []nyaa.Item) {
+				send(NyaaResultsMsg{Results: items, Partial: true, seq: seq, next: ch})
+			})
+			send(NyaaResultsMsg{Results: results, Err: err, paging: paging, seq: seq})
+		}()
+		return waitNyaaCmd(ch)()
+	}
+}
+
+// waitNyaaCmd waits for the next update of a streaming search.
+func waitNyaaCmd(ch <-chan NyaaResultsMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
 	}
 }
@@ -0,0 +1,48 @@
+package views
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rayanxn/ani-tui/internal/nyaa"
+)
+
+func TestTorrentsModel_MorePagesAfterFilteredFullPage(t *testing.T) {
+	t.Parallel()
+
+	m := NewTorrentsModel(1, nyaa.SearchRequest{PrimaryTitle: "My Anime", Episode: 1}, 0, nil, nyaa.Profile{})
+	defer m.Cleanup()
+
+	// Title filtering left a handful of results from a full raw page two.
+	var results []nyaa.Item
+	for i := range 10 {
+		results = append(results, nyaa.Item{Title: "[Sub] My Anime - 01", InfoHash: fmt.Sprint(i)})
+	}
+	m, _ = m.Update(NyaaResultsMsg{Results: results, paging: nyaa.Paging{Page: 2, More: true}, seq: m.searchSeq})
+	if !m.hasMore || m.page != 2 {
+		t.Fatalf("hasMore = %v, page = %d; want more after page 2", m.hasMore, m.page)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if cmd == nil || !m.paging || m.notice != "Loading page 3..." {
+		t.Fatalf("n: paging = %v, notice = %q; want page 3 requested", m.paging, m.notice)
+	}
+}
+
+func TestTorrentsModel_NoMorePagesAfterShortPage(t *testing.T) {
+	t.Parallel()
+
+	m := NewTorrentsModel(1, nyaa.SearchRequest{PrimaryTitle: "My Anime", Episode: 1}, 0, nil, nyaa.Profile{})
+	defer m.Cleanup()
+
+	results := []nyaa.Item{{Title: "[Sub] My Anime - 01", InfoHash: "a"}}
+	m, _ = m.Update(NyaaResultsMsg{Results: results, paging: nyaa.Paging{Page: 1}, seq: m.searchSeq})
+	if m.hasMore {
+		t.Fatal("a short raw page should not offer more")
+	}
+	if m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}); cmd != nil || m.paging {
+		t.Fatal("n should do nothing without more pages")
+	}
+}
@@ -1,27 +1,185 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/rayanxn/ani-tui/internal/anilist"
+	"github.com/rayanxn/ani-tui/internal/cache"
 	"github.com/rayanxn/ani-tui/internal/config"
+	"github.com/rayanxn/ani-tui/internal/downloads"
+	"github.com/rayanxn/ani-tui/internal/history"
+	"github.com/rayanxn/ani-tui/internal/nyaa"
+	"github.com/rayanxn/ani-tui/internal/termimg"
+	"github.com/rayanxn/ani-tui/internal/torrent"
 	"github.com/rayanxn/ani-tui/internal/ui/views"
 )
 
 func main() {
+	offline := flag.Bool("offline", false, "start offline: serve AniList data from the cache and play downloaded episodes only")
+	flag.Parse()
+
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
 		os.Exit(1)
 	}
+	cfg.Offline = *offline
+
+	indexPath, err := downloads.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to locate downloads index: %v\n", err)
+		os.Exit(1)
+	}
+	index, err := downloads.Open(indexPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load downloads index: %v\n", err)
+		os.Exit(1)
+	}
+
+	historyPath, err := history.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to locate watch history: %v\n", err)
+		os.Exit(1)
+	}
+	watched, err := history.Open(historyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load watch history: %v\n", err)
+		os.Exit(1)
+	}
+	sessionsPath, err := history.DefaultSessionsPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to locate watch sessions: %v\n", err)
+		os.Exit(1)
+	}
+	sessions, err := history.OpenSessions(sessionsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load watch sessions: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Caching is an optimisation; run without it if the directory is unusable.
+	var responses *cache.Cache
+	if dir, err := config.CacheDir(); err == nil {
+		responses, _ = cache.Open(dir)
+	}
+	nyaa.SetCache(responses)
 
-	app := views.NewAppModel(cfg)
+	dataDir, err := config.DataDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to locate data dir: %v\n", err)
+		os.Exit(1)
+	}
+	outbox, err := anilist.OpenOutbox(filepath.Join(dataDir, "outbox.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load offline outbox: %v\n", err)
+		os.Exit(1)
+	}
+
+	graphics, ok := termimg.ParseProtocol(cfg.CoverArt)
+	if !ok {
+		graphics = termimg.Detect(os.Getenv)
+	}
+
+	if cfg.DownloadDir == "" {
+		// Keep torrent data between sessions so finished episodes can be replayed.
+		if dir, err := config.DefaultDownloadDir(); err == nil {
+			cfg.DownloadDir = dir
+		}
+	}
+
+	// One torrent client serves both streaming and the download queue; two
+	// clients would fight over the listen port and the data directory.
+	// Without it the app still browses AniList and plays local files.
+	var torrentErr error
+	client, err := torrent.NewClient(torrent.ClientOptions{
+		DownloadDir:  cfg.DownloadDir,
+		DownloadRate: int64(cfg.MaxDownloadKBps) * 1024,
+		UploadRate:   int64(cfg.MaxUploadKBps) * 1024,
+		ThrottleRate: int64(cfg.ThrottleKBps) * 1024,
+		ListenPort:   cfg.ListenPort,
+		Seed: torrent.SeedPolicy{
+			Enabled: cfg.Seed,
+			Ratio:   cfg.SeedRatio,
+			Time:    time.Duration(cfg.SeedTimeMinutes) * time.Minute,
+		},
+	})
+	if err != nil {
+		torrentErr = err
+	}
+
+	var queue *torrent.Manager
+	if client != nil {
+		queue, err = torrent.NewManager(client, filepath.Join(dataDir, "queue.json"), torrent.ManagerOptions{
+			MaxActive: cfg.MaxActiveDownloads,
+			DiskQuota: int64(cfg.DiskQuotaGB * (1 << 30)),
+			OnComplete: func(it torrent.QueueItem) error {
+				return recordQueueItem(index, it)
+			},
+		})
+		if err != nil {
+			torrentErr = err
+		}
+	}
+
+	app := views.NewAppModel(cfg, views.Services{
+		Downloads:  index,
+		Torrents:   client,
+		Queue:      queue,
+		TorrentErr: torrentErr,
+		Cache:      responses,
+		Outbox:     outbox,
+		History:    watched,
+		Sessions:   sessions,
+		Graphics:   graphics,
+	})
 	p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
-	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	_, runErr := p.Run()
+	if queue != nil {
+		queue.Close()
+	}
+	if client != nil {
+		client.Close()
+	}
+	if torrentErr != nil {
+		fmt.Fprintf(os.Stderr, "Streaming and downloads were disabled: %v\n", torrentErr)
+	}
+	if runErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", runErr)
 		os.Exit(1)
 	}
 }
+
+// recordQueueItem adds the files of a completed download-queue item to the
+// downloads index. Batch files get their episode number from the file name;
+// files whose episode cannot be determined are skipped.
+func recordQueueItem(index *downloads.Index, it torrent.QueueItem) error {
+	for _, f := range it.Files {
+		episode := it.Episode
+		if it.Batch {
+			episode = nyaa.ParseRelease(filepath.Base(f.Path)).Episode
+		}
+		if episode <= 0 {
+			continue
+		}
+		err := index.Put(downloads.Entry{
+			MediaID:  it.MediaID,
+			Title:    it.Title,
+			Episode:  episode,
+			InfoHash: it.InfoHash,
+			Path:     f.Path,
+			Size:     f.Size,
+			Complete: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}